@@ -1,11 +1,39 @@
 package kdtree
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
 
 type Comparable[T any] interface {
 	fmt.Stringer
 	Order(rhs T, dim int) int
 	Dist(rhs T) int
 	DistDim(rhs T, dim int) int
-	Encode() []byte
+}
+
+// Dimensioned is implemented by values with a variable number of coordinates. Trees decoded from bytes or
+// JSON reject values whose number of coordinates is not the number of dimensions of the tree.
+type Dimensioned interface {
+	Dimensions() int
+}
+
+// FloatDistKey maps a non-negative floating point distance to an int that orders the same way, so that
+// values with floating point coordinates can implement Dist and DistDim without losing precision. Where
+// int has 32 bits, the distance is rounded to a float32 first, so distances closer than its precision
+// get the same key.
+func FloatDistKey(d float64) int {
+	if strconv.IntSize == 32 {
+		return int(math.Float32bits(float32(d)))
+	}
+	return int(math.Float64bits(d))
+}
+
+// FloatDistFromKey is the inverse of FloatDistKey.
+func FloatDistFromKey(key int) float64 {
+	if strconv.IntSize == 32 {
+		return float64(math.Float32frombits(uint32(key)))
+	}
+	return math.Float64frombits(uint64(key))
 }
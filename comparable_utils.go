@@ -1,6 +1,12 @@
 package kdtree
 
 func min[T Comparable[T]](lhs, rhs *T, tcd int) *T {
+	if lhs == nil {
+		return rhs
+	}
+	if rhs == nil {
+		return lhs
+	}
 	if (*lhs).Order(*rhs, tcd) < 0 {
 		return lhs
 	}
@@ -8,6 +14,12 @@ func min[T Comparable[T]](lhs, rhs *T, tcd int) *T {
 }
 
 func max[T Comparable[T]](lhs, rhs *T, tcd int) *T {
+	if lhs == nil {
+		return rhs
+	}
+	if rhs == nil {
+		return lhs
+	}
 	if (*lhs).Order(*rhs, tcd) > 0 {
 		return lhs
 	}
@@ -19,10 +31,6 @@ func distance[T Comparable[T]](src, dst *T) int {
 }
 
 func closest[T Comparable[T]](v, nn1, nn2 *T) *T {
-	if nn1 == nil && nn2 == nil {
-		panic("Both `nn1` and `nn2` inputs are nil!")
-	}
-
 	if nn1 == nil {
 		return nn2
 	}
@@ -111,8 +111,66 @@ func (rcv *KDTree) ItemsLength() int {
 	return 0
 }
 
+func (rcv *KDTree) ItemSize() uint32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(12))
+	if o != 0 {
+		return rcv._tab.GetUint32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *KDTree) MutateItemSize(n uint32) bool {
+	return rcv._tab.MutateUint32Slot(12, n)
+}
+
+func (rcv *KDTree) PackedItems(j int) byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
+	}
+	return 0
+}
+
+func (rcv *KDTree) PackedItemsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *KDTree) PackedItemsBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *KDTree) MutatePackedItems(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
+}
+
+func (rcv *KDTree) DeltaEncoded() bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+	if o != 0 {
+		return rcv._tab.GetBool(o + rcv._tab.Pos)
+	}
+	return false
+}
+
+func (rcv *KDTree) MutateDeltaEncoded(n bool) bool {
+	return rcv._tab.MutateBoolSlot(16, n)
+}
+
 func KDTreeStart(builder *flatbuffers.Builder) {
-	builder.StartObject(4)
+	builder.StartObject(7)
 }
 func KDTreeAddVersionNumber(builder *flatbuffers.Builder, versionNumber uint32) {
 	builder.PrependUint32Slot(0, versionNumber, 0)
@@ -132,6 +190,18 @@ func KDTreeAddItems(builder *flatbuffers.Builder, items flatbuffers.UOffsetT) {
 func KDTreeStartItemsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
 	return builder.StartVector(4, numElems, 4)
 }
+func KDTreeAddItemSize(builder *flatbuffers.Builder, itemSize uint32) {
+	builder.PrependUint32Slot(4, itemSize, 0)
+}
+func KDTreeAddPackedItems(builder *flatbuffers.Builder, packedItems flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(5, flatbuffers.UOffsetT(packedItems), 0)
+}
+func KDTreeStartPackedItemsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func KDTreeAddDeltaEncoded(builder *flatbuffers.Builder, deltaEncoded bool) {
+	builder.PrependBoolSlot(6, deltaEncoded, false)
+}
 func KDTreeEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
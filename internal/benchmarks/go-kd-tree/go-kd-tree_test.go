@@ -149,3 +149,38 @@ func BenchmarkGoKDTreeBalance(b *testing.B) {
 	}
 	runtime.KeepAlive(tree)
 }
+
+// shardedTrees splits the trace between two workers the way a sharded ingestion does.
+func shardedTrees() (*kdtree.KDTree[types.Tensor2D], *kdtree.KDTree[types.Tensor2D]) {
+	var lhs, rhs []types.Tensor2D
+	for i, e := range trace {
+		if i%2 == 0 {
+			lhs = append(lhs, e)
+		} else {
+			rhs = append(rhs, e)
+		}
+	}
+	return kdtree.NewKDTreeWithValues(dimensions2DCount, lhs), kdtree.NewKDTreeWithValues(dimensions2DCount, rhs)
+}
+
+func BenchmarkGoKDTreeMerge(b *testing.B) {
+	lhs, rhs := shardedTrees()
+	var tree *kdtree.KDTree[types.Tensor2D]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree, _ = kdtree.Merge(lhs, rhs)
+	}
+	runtime.KeepAlive(tree)
+}
+
+// BenchmarkGoKDTreeMergeRebuild merges by rebuilding the tree from the values of both trees, which is what
+// Merge avoids.
+func BenchmarkGoKDTreeMergeRebuild(b *testing.B) {
+	lhs, rhs := shardedTrees()
+	var tree *kdtree.KDTree[types.Tensor2D]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree = kdtree.NewKDTreeWithValues(dimensions2DCount, append(lhs.Values(), rhs.Values()...))
+	}
+	runtime.KeepAlive(tree)
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+
+	internal "github.com/rishitc/go-kd-tree/internal/utils"
 )
 
 type Tensor2D [2]int
@@ -59,3 +61,73 @@ func DecodeTensor2D(bytes []byte) Tensor2D {
 	}
 	return v
 }
+
+// Tensor2DFixedCodec encodes each coordinate as a little-endian 64-bit integer. As every value takes up
+// the same number of bytes, trees encoded with it store their values in a single packed array.
+type Tensor2DFixedCodec struct{}
+
+func (Tensor2DFixedCodec) Encode(v Tensor2D) ([]byte, error) {
+	return internal.EncodeFixedInts(v[:]), nil
+}
+
+func (Tensor2DFixedCodec) Decode(b []byte) (Tensor2D, error) {
+	var v Tensor2D
+	err := internal.DecodeFixedInts(b, v[:])
+	return v, err
+}
+
+func (Tensor2DFixedCodec) Name() string {
+	return "tensor2d/fixed64"
+}
+
+func (Tensor2DFixedCodec) Size() int {
+	return len(Tensor2D{}) * internal.FixedCoordinateSize
+}
+
+// Tensor2DVarintCodec encodes each coordinate as a zig-zag varint, which keeps small coordinates small. As
+// values take up different numbers of bytes, trees encoded with it store one table per value;
+// Tensor2DDeltaCodec stores them in a single packed array.
+type Tensor2DVarintCodec struct{}
+
+func (Tensor2DVarintCodec) Encode(v Tensor2D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], nil), nil
+}
+
+func (Tensor2DVarintCodec) Decode(b []byte) (Tensor2D, error) {
+	var v Tensor2D
+	err := internal.DecodeVarints(b, v[:], nil)
+	return v, err
+}
+
+func (Tensor2DVarintCodec) Name() string {
+	return "tensor2d/varint"
+}
+
+// Tensor2DDeltaCodec encodes each value as zig-zag varints of the differences of its coordinates to those of
+// the value stored before it, which keeps neighbouring values small. Trees encoded with it store their
+// values in a single packed array.
+type Tensor2DDeltaCodec struct{}
+
+func (Tensor2DDeltaCodec) Encode(v Tensor2D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], nil), nil
+}
+
+func (Tensor2DDeltaCodec) Decode(b []byte) (Tensor2D, error) {
+	var v Tensor2D
+	err := internal.DecodeVarints(b, v[:], nil)
+	return v, err
+}
+
+func (Tensor2DDeltaCodec) EncodeDelta(prev, v Tensor2D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], prev[:]), nil
+}
+
+func (Tensor2DDeltaCodec) DecodeDelta(prev Tensor2D, b []byte) (Tensor2D, error) {
+	var v Tensor2D
+	err := internal.DecodeVarints(b, v[:], prev[:])
+	return v, err
+}
+
+func (Tensor2DDeltaCodec) Name() string {
+	return "tensor2d/delta"
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+
+	internal "github.com/rishitc/go-kd-tree/internal/utils"
 )
 
 type Tensor3D [3]int
@@ -67,3 +69,73 @@ func DecodeTensor3D(bytes []byte) Tensor3D {
 	}
 	return v
 }
+
+// Tensor3DFixedCodec encodes each coordinate as a little-endian 64-bit integer. As every value takes up
+// the same number of bytes, trees encoded with it store their values in a single packed array.
+type Tensor3DFixedCodec struct{}
+
+func (Tensor3DFixedCodec) Encode(v Tensor3D) ([]byte, error) {
+	return internal.EncodeFixedInts(v[:]), nil
+}
+
+func (Tensor3DFixedCodec) Decode(b []byte) (Tensor3D, error) {
+	var v Tensor3D
+	err := internal.DecodeFixedInts(b, v[:])
+	return v, err
+}
+
+func (Tensor3DFixedCodec) Name() string {
+	return "tensor3d/fixed64"
+}
+
+func (Tensor3DFixedCodec) Size() int {
+	return len(Tensor3D{}) * internal.FixedCoordinateSize
+}
+
+// Tensor3DVarintCodec encodes each coordinate as a zig-zag varint, which keeps small coordinates small. As
+// values take up different numbers of bytes, trees encoded with it store one table per value;
+// Tensor3DDeltaCodec stores them in a single packed array.
+type Tensor3DVarintCodec struct{}
+
+func (Tensor3DVarintCodec) Encode(v Tensor3D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], nil), nil
+}
+
+func (Tensor3DVarintCodec) Decode(b []byte) (Tensor3D, error) {
+	var v Tensor3D
+	err := internal.DecodeVarints(b, v[:], nil)
+	return v, err
+}
+
+func (Tensor3DVarintCodec) Name() string {
+	return "tensor3d/varint"
+}
+
+// Tensor3DDeltaCodec encodes each value as zig-zag varints of the differences of its coordinates to those of
+// the value stored before it, which keeps neighbouring values small. Trees encoded with it store their
+// values in a single packed array.
+type Tensor3DDeltaCodec struct{}
+
+func (Tensor3DDeltaCodec) Encode(v Tensor3D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], nil), nil
+}
+
+func (Tensor3DDeltaCodec) Decode(b []byte) (Tensor3D, error) {
+	var v Tensor3D
+	err := internal.DecodeVarints(b, v[:], nil)
+	return v, err
+}
+
+func (Tensor3DDeltaCodec) EncodeDelta(prev, v Tensor3D) ([]byte, error) {
+	return internal.EncodeVarints(v[:], prev[:]), nil
+}
+
+func (Tensor3DDeltaCodec) DecodeDelta(prev Tensor3D, b []byte) (Tensor3D, error) {
+	var v Tensor3D
+	err := internal.DecodeVarints(b, v[:], prev[:])
+	return v, err
+}
+
+func (Tensor3DDeltaCodec) Name() string {
+	return "tensor3d/delta"
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// FixedCoordinateSize is the number of bytes of a coordinate encoded by EncodeFixedInts or EncodeFloats.
+const FixedCoordinateSize = 8
+
+// EncodeFixedInts encodes each coordinate as a little-endian 64-bit integer.
+func EncodeFixedInts(coordinates []int) []byte {
+	b := make([]byte, 0, len(coordinates)*FixedCoordinateSize)
+	for _, c := range coordinates {
+		b = binary.LittleEndian.AppendUint64(b, uint64(c))
+	}
+	return b
+}
+
+// DecodeFixedInts decodes the coordinates written by EncodeFixedInts into coordinates.
+func DecodeFixedInts(b []byte, coordinates []int) error {
+	if err := checkFixedSize(b, len(coordinates)); err != nil {
+		return err
+	}
+	for i := range coordinates {
+		c, err := toInt(int64(binary.LittleEndian.Uint64(b[i*FixedCoordinateSize:])), i)
+		if err != nil {
+			return err
+		}
+		coordinates[i] = c
+	}
+	return nil
+}
+
+// EncodeFloats encodes each coordinate as a little-endian IEEE 754 double.
+func EncodeFloats(coordinates []float64) []byte {
+	b := make([]byte, 0, len(coordinates)*FixedCoordinateSize)
+	for _, c := range coordinates {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(c))
+	}
+	return b
+}
+
+// DecodeFloats decodes the coordinates written by EncodeFloats into coordinates.
+func DecodeFloats(b []byte, coordinates []float64) error {
+	if err := checkFixedSize(b, len(coordinates)); err != nil {
+		return err
+	}
+	for i := range coordinates {
+		coordinates[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*FixedCoordinateSize:]))
+	}
+	return nil
+}
+
+// EncodeVarints encodes the difference of each coordinate to the same coordinate of origin as a zig-zag
+// varint. A nil origin encodes the coordinates themselves.
+func EncodeVarints(coordinates, origin []int) []byte {
+	b := make([]byte, 0, len(coordinates)*binary.MaxVarintLen64)
+	for i, c := range coordinates {
+		d := int64(c)
+		if origin != nil {
+			d -= int64(origin[i])
+		}
+		b = binary.AppendVarint(b, d)
+	}
+	return b
+}
+
+// DecodeVarints decodes the coordinates written by EncodeVarints with the same origin into coordinates.
+func DecodeVarints(b []byte, coordinates, origin []int) error {
+	for i := range coordinates {
+		d, n := binary.Varint(b)
+		if n <= 0 {
+			return fmt.Errorf("invalid varint for coordinate %d", i)
+		}
+		if origin != nil {
+			d += int64(origin[i])
+		}
+		c, err := toInt(d, i)
+		if err != nil {
+			return err
+		}
+		coordinates[i] = c
+		b = b[n:]
+	}
+	if len(b) != 0 {
+		return fmt.Errorf("%d trailing bytes after the coordinates", len(b))
+	}
+	return nil
+}
+
+func checkFixedSize(b []byte, n int) error {
+	if len(b) != n*FixedCoordinateSize {
+		return fmt.Errorf("expected %d bytes, got %d", n*FixedCoordinateSize, len(b))
+	}
+	return nil
+}
+
+// toInt converts the i-th coordinate c to an int, which fails where int has 32 bits.
+func toInt(c int64, i int) (int, error) {
+	if c < math.MinInt || c > math.MaxInt {
+		return 0, fmt.Errorf("coordinate %d (%d) does not fit in an int", i, c)
+	}
+	return int(c), nil
+}
@@ -2,6 +2,8 @@ package kdtree
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,34 +18,75 @@ func NewKDNode[T Comparable[T]](value T) *kdNode[T] {
 	}
 }
 
+// NewKDTreeWithValues returns a balanced tree of vs with d dimensions. It panics when d is not positive,
+// New with WithValues returns an error instead.
 func NewKDTreeWithValues[T Comparable[T]](d int, vs []T) *KDTree[T] {
-	size := len(vs)
-	initialIndices := make([][]int, d)
-	for cd := range initialIndices {
-		initialIndices[cd] = internal.IotaSlice(len(vs))
-		sort.Slice(initialIndices[cd], func(i, j int) bool {
-			return vs[initialIndices[cd][i]].Order(vs[initialIndices[cd][j]], cd) < 0
-		})
-	}
-	root := insertAllNew[T](vs, initialIndices, 0)
-	return &KDTree[T]{
-		dimensions: d,
-		root:       root,
-		isSetup:    true,
-		size:       size,
+	t, err := New(d, WithValues(vs))
+	if err != nil {
+		panic(err.Error())
 	}
+	return t
 }
 
 func NewKDTreeFromBytes[T Comparable[T]](encodedBytes []byte, decodeItemFunc func([]byte) T) *KDTree[T] {
+	codec := FuncCodec[T]{
+		DecodeFunc: func(b []byte) (T, error) {
+			return decodeItemFunc(b), nil
+		},
+	}
+	t, err := NewKDTreeFromBytesWith(encodedBytes, codec)
+	if err != nil {
+		panic(err.Error())
+	}
+	return t
+}
+
+// NewKDTreeFromBytesWith decodes a tree encoded by EncodeWith, using codec to decode its values. Like
+// NewKDTreeFromBytes, the decoded tree is balanced. The encoded tree is verified with
+// DefaultDecodeOptions.
+func NewKDTreeFromBytesWith[T Comparable[T]](encodedBytes []byte, codec Codec[T]) (*KDTree[T], error) {
+	return NewKDTreeFromBytesWithOptions(encodedBytes, codec, DefaultDecodeOptions)
+}
+
+// NewKDTreeFromBytesWithOptions works like NewKDTreeFromBytesWith and verifies the encoded tree with opts
+// before decoding it, returning an error wrapping ErrInvalidEncoding instead of panicking on malformed
+// input.
+func NewKDTreeFromBytesWithOptions[T Comparable[T]](encodedBytes []byte, codec Codec[T], opts DecodeOptions) (*KDTree[T], error) {
+	if err := verifyEncoding(encodedBytes, opts); err != nil {
+		return nil, err
+	}
 	tree := encoding.GetRootAsKDTree(encodedBytes, 0)
 	if encodingVersion != tree.VersionNumber() {
-		panic("Unsupported encoding version number!")
+		return nil, fmt.Errorf("%w: unsupported encoding version number %d", ErrInvalidEncoding, tree.VersionNumber())
 	}
 	itemsLength := tree.ItemsLength()
+	itemSize := int(tree.ItemSize())
+	packedItems := tree.PackedItemsBytes()
+	deltaCodec, isDeltaCodec := codec.(DeltaCodec[T])
+	var deltaItems [][]byte
+	if tree.DeltaEncoded() {
+		if !isDeltaCodec {
+			return nil, fmt.Errorf("%w: values were encoded by a delta codec", ErrInvalidEncoding)
+		}
+		var err error
+		if deltaItems, err = unpackDeltaItems(packedItems); err != nil {
+			return nil, err
+		}
+		itemsLength = len(deltaItems)
+	} else if itemSize > 0 {
+		if len(packedItems)%itemSize != 0 {
+			return nil, fmt.Errorf("%w: %d packed bytes do not hold items of %d bytes each",
+				ErrInvalidEncoding, len(packedItems), itemSize)
+		}
+		itemsLength = len(packedItems) / itemSize
+	}
 	if itemsLength != tree.InorderIndicesLength() {
-		msg := fmt.Sprintf("The number of the indices (%d) are not the same as the number of items(%d)!",
-			tree.InorderIndicesLength(), itemsLength)
-		panic(msg)
+		return nil, fmt.Errorf("%w: the number of the indices (%d) are not the same as the number of items (%d)",
+			ErrInvalidEncoding, tree.InorderIndicesLength(), itemsLength)
+	}
+	dimensions := int(tree.Dimensions())
+	if dimensions == 0 {
+		return nil, fmt.Errorf("%w: tree has no dimensions", ErrInvalidEncoding)
 	}
 	// Note: This will be useful when I need to reconstruct the exact tree again.
 	// For now the reconstructed tree will not be exactly the same. It will be a rebalanced tree.
@@ -58,41 +101,98 @@ func NewKDTreeFromBytes[T Comparable[T]](encodedBytes []byte, decodeItemFunc fun
 	// 	inorderIndexLookup[idx] = i
 	// }
 	items := make([]T, itemsLength)
+	var prev T
 	for i := 0; i < itemsLength; i++ {
+		if tree.DeltaEncoded() {
+			item, err := deltaCodec.DecodeDelta(prev, deltaItems[i])
+			if err != nil {
+				return nil, err
+			}
+			items[i], prev = item, item
+			continue
+		}
+		if itemSize > 0 {
+			item, err := codec.Decode(packedItems[i*itemSize : (i+1)*itemSize])
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+			continue
+		}
 		itemPtr := new(encoding.Item)
 		if tree.Items(itemPtr, i) {
-			item := decodeItemFunc(itemPtr.DataBytes())
+			item, err := codec.Decode(itemPtr.DataBytes())
+			if err != nil {
+				return nil, err
+			}
 			items[i] = item
 		}
 	}
-	dimensions := int(tree.Dimensions())
-	return NewKDTreeWithValues(dimensions, items)
+	if err := checkDimensions(dimensions, items); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	return NewKDTreeWithValues(dimensions, items), nil
 }
 
-func (t *KDTree[T]) FindMin(targetDimension int) (T, bool) {
-	if t.root == nil || targetDimension >= t.dimensions {
-		return t.zeroVal, false
+// FindMin returns the smallest value of the tree in targetDimension. It returns ErrEmptyTree when the
+// tree holds no values.
+func (t *KDTree[T]) FindMin(targetDimension int) (T, error) {
+	if err := t.checkDimension(targetDimension); err != nil {
+		return t.zeroVal, err
 	}
-	res := findMin(t.dimensions, targetDimension, 0, t.root)
+	res := findMin(t.dimensions, targetDimension, 0, t.root, true)
 	if res == nil {
-		return t.zeroVal, false
+		return t.zeroVal, ErrEmptyTree
 	}
-	return *res, true
+	return *res, nil
 }
 
-func (t *KDTree[T]) FindMax(targetDimension int) (T, bool) {
-	if t.root == nil || targetDimension >= t.dimensions {
-		return t.zeroVal, false
+// FindMax returns the largest value of the tree in targetDimension. It returns ErrEmptyTree when the
+// tree holds no values.
+func (t *KDTree[T]) FindMax(targetDimension int) (T, error) {
+	if err := t.checkDimension(targetDimension); err != nil {
+		return t.zeroVal, err
 	}
-	res := findMax(t.dimensions, targetDimension, 0, t.root)
+	res := findMax(t.dimensions, targetDimension, 0, t.root, true)
 	if res == nil {
-		return t.zeroVal, false
+		return t.zeroVal, ErrEmptyTree
 	}
-	return *res, true
+	return *res, nil
+}
+
+// checkDimension reports whether the tree is setup and dim is one of its dimensions.
+func (t *KDTree[T]) checkDimension(dim int) error {
+	if !t.isSetup {
+		return ErrTreeNotSetup
+	}
+	if dim < 0 || dim >= t.dimensions {
+		return fmt.Errorf("%w: dimension %d of a tree with %d dimensions", ErrInvalidDimension, dim, t.dimensions)
+	}
+	return nil
 }
 
 func (t *KDTree[T]) NearestNeighbor(value T) (T, bool) {
-	res := nearestNeighbor(t.dimensions, &value, nil, 0, t.root)
+	return t.NearestNeighborWithStats(value, nil)
+}
+
+// NearestNeighborWithStats works like NearestNeighbor and records the work done by the search in stats,
+// which may be nil.
+func (t *KDTree[T]) NearestNeighborWithStats(value T, stats *QueryStats) (T, bool) {
+	tr := newQueryTrace[T](stats)
+	var res *T
+	resDist := 0
+	offsets := t.imageOffsets()
+	bounds := t.imageBounds(value, offsets)
+	for i, offset := range offsets {
+		if res != nil && bounds[i] > resDist {
+			continue
+		}
+		image := translate(value, offset)
+		nn := nearestNeighbor(t.dimensions, &image, nil, 0, t.root, 0, tr)
+		if nn != nil && (res == nil || distance(&image, nn) < resDist) {
+			res, resDist = nn, distance(&image, nn)
+		}
+	}
 	if res == nil {
 		return t.zeroVal, false
 	}
@@ -100,28 +200,113 @@ func (t *KDTree[T]) NearestNeighbor(value T) (T, bool) {
 }
 
 func (t *KDTree[T]) RangeSearch(getRelativePosition RangeFunc[T]) []T {
+	return t.RangeSearchWithStats(getRelativePosition, nil)
+}
+
+// RangeSearchWithStats works like RangeSearch and records the work done by the search in stats, which may
+// be nil.
+func (t *KDTree[T]) RangeSearchWithStats(getRelativePosition RangeFunc[T], stats *QueryStats) []T {
 	var res []T
-	rangeSearch(getRelativePosition, t.dimensions, &res, t.root, 0)
+	tr := newQueryTrace[T](stats)
+	for _, offset := range t.imageOffsets() {
+		getImagePosition := getRelativePosition
+		if offset != nil {
+			getImagePosition = func(v T, dim int) RelativePosition {
+				return getRelativePosition(translate(v, offset), dim)
+			}
+		}
+		rangeSearch(getImagePosition, t.dimensions, &res, t.root, 0, 0, tr)
+	}
+	if t.periods != nil {
+		res = dedupe(res)
+	}
 	return res
 }
 
+// RadiusSearch returns every value whose distance to center, as measured by Dist, is at most radius.
+func (t *KDTree[T]) RadiusSearch(center T, radius int) []T {
+	return t.RadiusSearchWithStats(center, radius, nil)
+}
+
+// RadiusSearchWithStats works like RadiusSearch and records the work done by the search in stats, which
+// may be nil.
+func (t *KDTree[T]) RadiusSearchWithStats(center T, radius int, stats *QueryStats) []T {
+	var res []T
+	tr := newQueryTrace[T](stats)
+	offsets := t.imageOffsets()
+	bounds := t.imageBounds(center, offsets)
+	for i, offset := range offsets {
+		if bounds[i] > radius {
+			continue
+		}
+		image := translate(center, offset)
+		radiusSearch(t.dimensions, &image, radius, &res, t.root, 0, 0, tr)
+	}
+	if t.periods != nil {
+		res = dedupe(res)
+	}
+	return res
+}
+
+// Len returns the number of values in the tree, not counting values removed lazily.
+func (t *KDTree[T]) Len() int {
+	return t.size
+}
+
+// Dimensions returns the number of dimensions of the tree.
+func (t *KDTree[T]) Dimensions() int {
+	return t.dimensions
+}
+
 func (t *KDTree[T]) Values() []T {
 	res := make([]T, 0, t.size)
 	valuesImpl(t.root, &res)
 	return res
 }
 
-func (t *KDTree[T]) Insert(value T) {
+// Insert adds value to the tree. It returns ErrTreeNotSetup when the tree was not created by one of the
+// constructors and an error wrapping ErrOutsidePeriod when a periodic tree can not hold value.
+func (t *KDTree[T]) Insert(value T) error {
+	if !t.isSetup {
+		return ErrTreeNotSetup
+	}
+	if err := checkPeriods(t.periods, value); err != nil {
+		return err
+	}
 	if t.root == nil {
 		t.root = NewKDNode(value)
-		return
+		t.size++
+		return nil
+	}
+	if t.tombstones > 0 {
+		if _, n := findPath(t.dimensions, value, t.root); n != nil && n.deleted {
+			n.deleted = false
+			t.tombstones--
+			t.size++
+			return nil
+		}
 	}
 	if insert(t.dimensions, value, 0, t.root) {
 		t.size++
 	}
+	return nil
 }
 
 func (t *KDTree[T]) Remove(value T) bool {
+	if t.lazyDelete {
+		_, n := findPath(t.dimensions, value, t.root)
+		if n == nil || n.deleted {
+			return false
+		}
+		n.deleted = true
+		t.tombstones++
+		t.size--
+		if t.compactRatio > 0 && float64(t.tombstones) > t.compactRatio*float64(t.size+t.tombstones) {
+			t.Compact()
+		}
+		return true
+	}
+
 	ok := false
 	t.root, ok = removeNode(t.dimensions, value, 0, t.root)
 	if ok {
@@ -130,14 +315,235 @@ func (t *KDTree[T]) Remove(value T) bool {
 	return ok
 }
 
-func valuesImpl[T Comparable[T]](r *kdNode[T], res *[]T) {
+// Clone returns a deep copy of the tree that preserves its exact structure.
+func (t *KDTree[T]) Clone() *KDTree[T] {
+	c := *t
+	c.root = cloneNode(t.root)
+	return &c
+}
+
+// Clear removes all values from the tree.
+func (t *KDTree[T]) Clear() {
+	t.root = nil
+	t.size = 0
+	t.tombstones = 0
+}
+
+// Equal reports whether both trees hold the same values in exactly the same structure.
+func (t *KDTree[T]) Equal(other *KDTree[T]) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	if t.dimensions != other.dimensions || t.size != other.size {
+		return false
+	}
+	stk := [][2]*kdNode[T]{{t.root, other.root}}
+	for len(stk) != 0 {
+		p, q := stk[len(stk)-1][0], stk[len(stk)-1][1]
+		stk = stk[:len(stk)-1]
+		if p != nil && q != nil && p.deleted == q.deleted && p.value.Dist(q.value) == 0 {
+			stk = append(stk, [2]*kdNode[T]{p.left, q.left}, [2]*kdNode[T]{p.right, q.right})
+		} else if p != nil || q != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// SameSet reports whether both trees hold the same values, regardless of how they are structured.
+func (t *KDTree[T]) SameSet(other *KDTree[T]) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	if t.dimensions != other.dimensions || t.size != other.size {
+		return false
+	}
+	for _, v := range t.Values() {
+		if _, n := findPath(other.dimensions, v, other.root); n == nil || n.deleted {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneNode[T Comparable[T]](r *kdNode[T]) *kdNode[T] {
+	if r == nil {
+		return nil
+	}
+	return &kdNode[T]{
+		value:   r.value,
+		left:    cloneNode(r.left),
+		right:   cloneNode(r.right),
+		deleted: r.deleted,
+	}
+}
+
+// Merge returns a new tree holding the values of both a and b, which are left unchanged. The values of
+// the smaller tree are inserted into a copy of the larger tree so that its already built structure is
+// reused instead of sorting every value again. Only the subtrees the insertions leave unbalanced are
+// rebuilt, so merging trees whose values are spread over the same space, like the trees built by the
+// workers of a sharded ingestion, sorts little or nothing again.
+func Merge[T Comparable[T]](a, b *KDTree[T]) (*KDTree[T], error) {
+	if a == nil || b == nil || !a.isSetup || !b.isSetup {
+		return nil, ErrTreeNotSetup
+	}
+	if a.dimensions != b.dimensions {
+		return nil, ErrDimensionMismatch
+	}
+	if a.size < b.size {
+		a, b = b, a
+	}
+
+	res := a.Clone()
+	res.Compact()
+	for _, v := range b.Values() {
+		if err := res.Insert(v); err != nil {
+			return nil, err
+		}
+	}
+	res.root = rebalance(res.dimensions, res.root, 0)
+	return res, nil
+}
+
+// rebalance rebuilds the topmost subtrees of r in which one child holds more than three quarters of the
+// nodes. r must not contain deleted nodes.
+func rebalance[T Comparable[T]](d int, r *kdNode[T], cd int) *kdNode[T] {
+	const minRebuildSize = 8
 	if r == nil {
+		return nil
+	}
+	l, h := countNodes(r.left), countNodes(r.right)
+	if l < h {
+		l, h = h, l
+	}
+	if n := l + h + 1; n >= minRebuildSize && 4*l > 3*n {
+		vs := make([]T, 0, n)
+		valuesImpl(r, &vs)
+		return newSubtree(d, vs, cd)
+	}
+	ncd := (cd + 1) % d
+	r.left = rebalance(d, r.left, ncd)
+	r.right = rebalance(d, r.right, ncd)
+	return r
+}
+
+// Split partitions the values of the tree by the hyperplane through pivot in the dimension dim. Values
+// ordered before pivot in that dimension end up in lo and all other values in hi. The tree itself is left
+// unchanged.
+func (t *KDTree[T]) Split(dim int, pivot T) (lo, hi *KDTree[T], err error) {
+	if err := t.checkDimension(dim); err != nil {
+		return nil, nil, err
+	}
+	var los, his []T
+	splitValues(t.dimensions, dim, pivot, t.root, 0, &los, &his)
+	return t.withValues(los), t.withValues(his), nil
+}
+
+// withValues returns a balanced tree holding vs, with the same settings as t.
+func (t *KDTree[T]) withValues(vs []T) *KDTree[T] {
+	root := newSubtree(t.dimensions, vs, 0)
+	return &KDTree[T]{
+		dimensions:   t.dimensions,
+		root:         root,
+		isSetup:      true,
+		size:         countNodes(root),
+		lazyDelete:   t.lazyDelete,
+		compactRatio: t.compactRatio,
+		periods:      t.periods,
+	}
+}
+
+// Update replaces oldValue with newValue and returns false if oldValue is not in the tree. When newValue
+// still lies within the cell of the node holding oldValue, the node is updated in place; otherwise Update
+// falls back to removing oldValue and inserting newValue.
+func (t *KDTree[T]) Update(oldValue, newValue T) bool {
+	path, n := findPath(t.dimensions, oldValue, t.root)
+	if n == nil || n.deleted {
+		return false
+	}
+	if fitsCell(t.dimensions, path, n, newValue) {
+		n.value = newValue
+		return true
+	}
+	t.Remove(oldValue)
+	t.Insert(newValue)
+	return true
+}
+
+// RemoveRange removes every value for which getRelativePosition reports InRange in a single traversal
+// of the tree and returns the number of values removed. Subtrees that lose their root are rebuilt from
+// their remaining values.
+func (t *KDTree[T]) RemoveRange(getRelativePosition RangeFunc[T]) int {
+	removed, purged := 0, 0
+	t.root = removeRange(getRelativePosition, t.dimensions, t.root, 0, &removed, &purged)
+	t.size -= removed
+	t.tombstones -= purged
+	return removed
+}
+
+// RemoveWhere removes every value for which shouldRemove returns true and returns the number of values
+// removed. As the predicate says nothing about where the values lie, every node of the tree is visited.
+func (t *KDTree[T]) RemoveWhere(shouldRemove func(T) bool) int {
+	return t.RemoveRange(func(v T, dim int) RelativePosition {
+		if dim == -1 && !shouldRemove(v) {
+			return AfterRange
+		}
+		return InRange
+	})
+}
+
+// SetLazyDelete switches Remove between restructuring the tree and only marking the removed node as
+// deleted. Deleted nodes are skipped by all queries until the tree is compacted, which happens
+// automatically once they make up more than compactRatio of the nodes in the tree. A compactRatio of 0
+// leaves compaction to explicit calls of Compact. Disabling lazy deletion compacts the tree. It returns an
+// error, leaving the tree unchanged, when compactRatio is not between 0 and 1.
+func (t *KDTree[T]) SetLazyDelete(enabled bool, compactRatio float64) error {
+	if !(compactRatio >= 0 && compactRatio <= 1) {
+		return fmt.Errorf("compact ratio must be between 0 and 1, got %v", compactRatio)
+	}
+	t.lazyDelete = enabled
+	t.compactRatio = compactRatio
+	if !enabled {
+		t.Compact()
+	}
+	return nil
+}
+
+// Compact physically removes the nodes marked as deleted by a lazy Remove, rebuilding only the subtrees
+// that contain them.
+func (t *KDTree[T]) Compact() {
+	if t.tombstones == 0 {
 		return
 	}
+	t.root = compact(t.dimensions, t.root, 0)
+	t.tombstones = 0
+}
 
-	*res = append(*res, r.value)
-	valuesImpl(r.left, res)
-	valuesImpl(r.right, res)
+func compact[T Comparable[T]](d int, r *kdNode[T], cd int) *kdNode[T] {
+	if r == nil {
+		return nil
+	}
+
+	if r.deleted {
+		var vs []T
+		valuesImpl(r.left, &vs)
+		valuesImpl(r.right, &vs)
+		return newSubtree(d, vs, cd)
+	}
+
+	ncd := (cd + 1) % d
+	r.left = compact(d, r.left, ncd)
+	r.right = compact(d, r.right, ncd)
+	return r
+}
+
+func valuesImpl[T Comparable[T]](r *kdNode[T], res *[]T) {
+	walk(r, PreOrder, func(n *kdNode[T], _ int) bool {
+		if !n.deleted {
+			*res = append(*res, n.value)
+		}
+		return true
+	})
 }
 
 func (t *KDTree[T]) String() string {
@@ -150,6 +556,9 @@ func (t *KDTree[T]) String() string {
 			n, _ := q.Pop()
 			if n != nil {
 				b.WriteString(n.value.String())
+				if n.deleted {
+					b.WriteString(" (deleted)")
+				}
 				b.WriteString(", ")
 				q.Push(n.left)
 				q.Push(n.right)
@@ -162,65 +571,157 @@ func (t *KDTree[T]) String() string {
 	return b.String()
 }
 
+// DotOptions configures the output of DotWithOptions.
+type DotOptions[T Comparable[T]] struct {
+	// MaxDepth, when positive, limits the depth of the nodes drawn. Each subtree below it is collapsed
+	// into a single placeholder node showing how many nodes it holds.
+	MaxDepth int
+	// ShowSplitDimension adds the dimension each node splits on to its label.
+	ShowSplitDimension bool
+	// OmitNilLeaves leaves out the point nodes drawn for missing children.
+	OmitNilLeaves bool
+	// HighlightQuery, when set, highlights the nodes visited by NearestNeighbor when searching for it.
+	HighlightQuery *T
+}
+
+type dotWriter[T Comparable[T]] struct {
+	b          strings.Builder
+	nodeCount  int
+	dimensions int
+	opts       DotOptions[T]
+	visited    map[*kdNode[T]]bool
+}
+
 // Implementation inspired by: https://eli.thegreenplace.net/2009/11/23/visualizing-binary-trees-with-graphviz
 func (t *KDTree[T]) Dot() string {
-	b := strings.Builder{}
-	b.WriteString("digraph BST {\n")
+	return t.DotWithOptions(DotOptions[T]{})
+}
+
+// DotWithOptions works like Dot, with opts controlling what is drawn to keep large trees readable.
+func (t *KDTree[T]) DotWithOptions(opts DotOptions[T]) string {
+	w := dotWriter[T]{
+		dimensions: t.dimensions,
+		opts:       opts,
+	}
+	if opts.HighlightQuery != nil {
+		tr := &queryTrace[T]{
+			stats:   &QueryStats{},
+			visited: map[*kdNode[T]]bool{},
+		}
+		nearestNeighbor(t.dimensions, opts.HighlightQuery, nil, 0, t.root, 0, tr)
+		w.visited = tr.visited
+	}
+
+	w.b.WriteString("digraph BST {\n")
 
 	node := t.root
 	if node == nil {
-		b.WriteString("\n")
+		w.b.WriteString("\n")
 	} else {
-		nodeCount := 0
-		currentNode := fmt.Sprintf("node%d", nodeCount)
-		nodeCount++
-		currNodeDef := fmt.Sprintf("    %s [label=\"%s\"]\n", currentNode, node.value.String())
-		b.WriteString(currNodeDef)
-		dot(node, &b, &nodeCount, currentNode)
+		currentNode := w.nextNode()
+		currNodeDef := fmt.Sprintf("    %s [%s]\n", currentNode, w.attributes(node, 0))
+		w.b.WriteString(currNodeDef)
+		w.children(node, currentNode, 0)
 	}
 
-	b.WriteString("}\n")
-	return b.String()
+	w.b.WriteString("}\n")
+	return w.b.String()
 }
 
-func dot[T Comparable[T]](node *kdNode[T], b *strings.Builder, nodeCount *int, currentNode string) {
-	leftNode := fmt.Sprintf("node%d", *nodeCount)
-	*nodeCount++
-	if node.left != nil {
-		leftNodeDef := fmt.Sprintf("    %s [label=\"%s\"];\n", leftNode, node.left.value.String())
-		b.WriteString(leftNodeDef)
-		b.WriteString(fmt.Sprintf("    %s -> %s;\n", currentNode, leftNode))
-		dot(node.left, b, nodeCount, leftNode)
-	} else {
-		leftNodeDef := fmt.Sprintf("    %s [shape=point];\n", leftNode)
-		b.WriteString(leftNodeDef)
-		b.WriteString(fmt.Sprintf("    %s -> %s;\n", currentNode, leftNode))
-	}
-
-	rightNode := fmt.Sprintf("node%d", *nodeCount)
-	*nodeCount++
-	if node.right != nil {
-		rightNodeDef := fmt.Sprintf("    %s [label=\"%s\"];\n", rightNode, node.right.value.String())
-		b.WriteString(rightNodeDef)
-		b.WriteString(fmt.Sprintf("    %s -> %s;\n", currentNode, rightNode))
-		dot(node.right, b, nodeCount, rightNode)
-	} else {
-		rightNodeDef := fmt.Sprintf("    %s [shape=point];\n", rightNode)
-		b.WriteString(rightNodeDef)
-		b.WriteString(fmt.Sprintf("    %s -> %s;\n", currentNode, rightNode))
+func (w *dotWriter[T]) nextNode() string {
+	name := fmt.Sprintf("node%d", w.nodeCount)
+	w.nodeCount++
+	return name
+}
+
+func (w *dotWriter[T]) children(node *kdNode[T], currentNode string, depth int) {
+	collapse := w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth
+	for _, child := range [2]*kdNode[T]{node.left, node.right} {
+		if child == nil && w.opts.OmitNilLeaves {
+			continue
+		}
+
+		childNode := w.nextNode()
+		var childNodeDef string
+		if child == nil {
+			childNodeDef = fmt.Sprintf("    %s [shape=point];\n", childNode)
+		} else if collapse {
+			childNodeDef = fmt.Sprintf("    %s [label=\"… %d nodes\", shape=box, style=dotted];\n", childNode, countNodes(child))
+		} else {
+			childNodeDef = fmt.Sprintf("    %s [%s];\n", childNode, w.attributes(child, depth+1))
+		}
+		w.b.WriteString(childNodeDef)
+
+		if child != nil && w.visited[node] && w.visited[child] {
+			w.b.WriteString(fmt.Sprintf("    %s -> %s [color=red];\n", currentNode, childNode))
+		} else {
+			w.b.WriteString(fmt.Sprintf("    %s -> %s;\n", currentNode, childNode))
+		}
+
+		if child != nil && !collapse {
+			w.children(child, childNode, depth+1)
+		}
+	}
+}
+
+func (w *dotWriter[T]) attributes(node *kdNode[T], depth int) string {
+	label := node.value.String()
+	if w.opts.ShowSplitDimension {
+		label += fmt.Sprintf("\\ndim %d", depth%w.dimensions)
+	}
+	attributes := fmt.Sprintf("label=\"%s\"", label)
+	if node.deleted {
+		attributes += ", style=dashed"
+	}
+	if w.visited[node] {
+		attributes += ", color=red, fontcolor=red"
+	}
+	return attributes
+}
+
+func countNodes[T Comparable[T]](r *kdNode[T]) int {
+	if r == nil {
+		return 0
 	}
+	return 1 + countNodes(r.left) + countNodes(r.right)
 }
 
 const encodingVersion uint32 = 0
 
+// Encode encodes the tree using the Encode() []byte method of its values, and panics if they do not have
+// one.
+//
+// Deprecated: Comparable no longer requires an Encode method, so most values, including the types of the
+// points package, can not be encoded this way. Use EncodeWith to pick how the values are encoded.
 func (t *KDTree[T]) Encode() []byte {
-	encodedPreorderItems := preorderTraversal(t.root)
+	if _, ok := any(t.zeroVal).(interface{ Encode() []byte }); !ok {
+		panic(fmt.Sprintf("%v has no Encode() []byte method, use EncodeWith", reflect.TypeFor[T]()))
+	}
+	b, err := t.EncodeWith(methodCodec[T]{})
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
+
+// EncodeWith encodes the tree into bytes, using codec to encode its values.
+func (t *KDTree[T]) EncodeWith(codec Codec[T]) ([]byte, error) {
+	if !t.isSetup {
+		return nil, ErrTreeNotSetup
+	}
+	root := t.root
+	if t.tombstones > 0 {
+		root = newSubtree(t.dimensions, t.Values(), 0)
+	}
+	encodedPreorderItems, err := preorderTraversal(root, codec)
+	if err != nil {
+		return nil, err
+	}
 	itemCount := len(encodedPreorderItems)
 	if itemCount != t.size {
-		msg := fmt.Sprintf("itemCount (%d) and t.size (%d) don't have the same size! Some bookkeeping has gone wrong!", itemCount, t.size)
-		panic(msg)
+		return nil, fmt.Errorf("%w: itemCount (%d) and t.size (%d) don't have the same size", ErrInvalidTree, itemCount, t.size)
 	}
-	encodedInorderIndices := inorderTraversal(t.root, t.size)
+	encodedInorderIndices := inorderTraversal(root, t.size)
 
 	builder := flatbuffers.NewBuilder(256)
 
@@ -231,6 +732,42 @@ func (t *KDTree[T]) Encode() []byte {
 	}
 	inorderIndices := builder.EndVector(itemCount)
 
+	if _, ok := codec.(DeltaCodec[T]); ok {
+		packedItems := builder.CreateByteVector(packDeltaItems(encodedPreorderItems))
+
+		encoding.KDTreeStart(builder)
+		encoding.KDTreeAddVersionNumber(builder, encodingVersion)
+		encoding.KDTreeAddDimensions(builder, uint32(t.dimensions))
+		encoding.KDTreeAddInorderIndices(builder, inorderIndices)
+		encoding.KDTreeAddPackedItems(builder, packedItems)
+		encoding.KDTreeAddDeltaEncoded(builder, true)
+		encodedKDTree := encoding.KDTreeEnd(builder)
+		builder.Finish(encodedKDTree)
+		return builder.FinishedBytes(), nil
+	}
+
+	if fixedSizeCodec, ok := codec.(FixedSizeCodec[T]); ok {
+		itemSize := fixedSizeCodec.Size()
+		packedBytes := make([]byte, 0, itemCount*itemSize)
+		for _, item := range encodedPreorderItems {
+			if len(item) != itemSize {
+				return nil, fmt.Errorf("fixed size codec encoded an item into %d bytes instead of %d", len(item), itemSize)
+			}
+			packedBytes = append(packedBytes, item...)
+		}
+		packedItems := builder.CreateByteVector(packedBytes)
+
+		encoding.KDTreeStart(builder)
+		encoding.KDTreeAddVersionNumber(builder, encodingVersion)
+		encoding.KDTreeAddDimensions(builder, uint32(t.dimensions))
+		encoding.KDTreeAddInorderIndices(builder, inorderIndices)
+		encoding.KDTreeAddItemSize(builder, uint32(itemSize))
+		encoding.KDTreeAddPackedItems(builder, packedItems)
+		encodedKDTree := encoding.KDTreeEnd(builder)
+		builder.Finish(encodedKDTree)
+		return builder.FinishedBytes(), nil
+	}
+
 	var encodedItems []flatbuffers.UOffsetT
 	for i := 0; i < itemCount; i++ {
 		item := encodedPreorderItems[i]
@@ -263,51 +800,162 @@ func (t *KDTree[T]) Encode() []byte {
 	encoding.KDTreeAddItems(builder, items)
 	encodedKDTree := encoding.KDTreeEnd(builder)
 	builder.Finish(encodedKDTree)
-	return builder.FinishedBytes()
+	return builder.FinishedBytes(), nil
 }
 
 // Balance rebalance the k-d tree by recreating it.
 func (t *KDTree[T]) Balance() {
-	t.root = NewKDTreeWithValues(t.dimensions, t.Values()).root
+	t.root = newSubtree(t.dimensions, t.Values(), 0)
+	t.tombstones = 0
 }
 
-func rangeSearch[T Comparable[T]](getRelativePosition RangeFunc[T], d int, res *[]T, r *kdNode[T], cd int) {
+func rangeSearch[T Comparable[T]](getRelativePosition RangeFunc[T], d int, res *[]T, r *kdNode[T], cd, depth int, tr *queryTrace[T]) {
 	if r == nil {
 		return
 	}
+	tr.visit(r, depth)
 
-	rel := getRelativePosition(r.value, -1)
-	if rel == InRange {
+	if !r.deleted && getRelativePosition(r.value, -1) == InRange {
 		*res = append(*res, r.value)
 	}
 
 	ncd := (cd + 1) % d
 	switch relInCD := getRelativePosition(r.value, cd); relInCD {
 	case BeforeRange:
-		rangeSearch(getRelativePosition, d, res, r.right, ncd)
+		if r.left != nil {
+			tr.prune()
+		}
+		rangeSearch(getRelativePosition, d, res, r.right, ncd, depth+1, tr)
 	case AfterRange:
-		rangeSearch(getRelativePosition, d, res, r.left, ncd)
+		if r.right != nil {
+			tr.prune()
+		}
+		rangeSearch(getRelativePosition, d, res, r.left, ncd, depth+1, tr)
 	case InRange:
-		rangeSearch(getRelativePosition, d, res, r.left, ncd)
-		rangeSearch(getRelativePosition, d, res, r.right, ncd)
+		rangeSearch(getRelativePosition, d, res, r.left, ncd, depth+1, tr)
+		rangeSearch(getRelativePosition, d, res, r.right, ncd, depth+1, tr)
 	default:
 		panic(fmt.Sprintf("Invalid value returned: %v", relInCD))
 	}
 }
 
-func preorderTraversal[T Comparable[T]](r *kdNode[T]) [][]byte {
-	var res [][]byte
-	preorderTraversalImpl(r, &res)
-	return res
+func radiusSearch[T Comparable[T]](d int, v *T, radius int, res *[]T, r *kdNode[T], cd, depth int, tr *queryTrace[T]) {
+	if r == nil {
+		return
+	}
+	tr.visit(r, depth)
+
+	if !r.deleted {
+		tr.evaluate()
+		if distance(v, &r.value) <= radius {
+			*res = append(*res, r.value)
+		}
+	}
+
+	var nextBranch, otherBranch *kdNode[T]
+	if (*v).Order(r.value, cd) < 0 {
+		nextBranch, otherBranch = r.left, r.right
+	} else {
+		nextBranch, otherBranch = r.right, r.left
+	}
+	ncd := (cd + 1) % d
+	radiusSearch(d, v, radius, res, nextBranch, ncd, depth+1, tr)
+	if internal.Abs((*v).DistDim(r.value, cd)) <= radius {
+		radiusSearch(d, v, radius, res, otherBranch, ncd, depth+1, tr)
+	} else if otherBranch != nil {
+		tr.prune()
+	}
+}
+
+func removeRange[T Comparable[T]](getRelativePosition RangeFunc[T], d int, r *kdNode[T], cd int, removed, purged *int) *kdNode[T] {
+	if r == nil {
+		return nil
+	}
+
+	if !r.deleted && getRelativePosition(r.value, -1) == InRange {
+		*removed++
+		var vs []T
+		collectOutOfRange(getRelativePosition, r.left, &vs, removed, purged)
+		collectOutOfRange(getRelativePosition, r.right, &vs, removed, purged)
+		return newSubtree(d, vs, cd)
+	}
+
+	ncd := (cd + 1) % d
+	switch relInCD := getRelativePosition(r.value, cd); relInCD {
+	case BeforeRange:
+		r.right = removeRange(getRelativePosition, d, r.right, ncd, removed, purged)
+	case AfterRange:
+		r.left = removeRange(getRelativePosition, d, r.left, ncd, removed, purged)
+	case InRange:
+		r.left = removeRange(getRelativePosition, d, r.left, ncd, removed, purged)
+		r.right = removeRange(getRelativePosition, d, r.right, ncd, removed, purged)
+	default:
+		panic(fmt.Sprintf("Invalid value returned: %v", relInCD))
+	}
+	return r
 }
 
-func preorderTraversalImpl[T Comparable[T]](r *kdNode[T], res *[][]byte) {
+func collectOutOfRange[T Comparable[T]](getRelativePosition RangeFunc[T], r *kdNode[T], res *[]T, removed, purged *int) {
 	if r == nil {
 		return
 	}
-	*res = append(*res, r.value.Encode())
-	preorderTraversalImpl(r.left, res)
-	preorderTraversalImpl(r.right, res)
+
+	if r.deleted {
+		*purged++
+	} else if getRelativePosition(r.value, -1) == InRange {
+		*removed++
+	} else {
+		*res = append(*res, r.value)
+	}
+	collectOutOfRange(getRelativePosition, r.left, res, removed, purged)
+	collectOutOfRange(getRelativePosition, r.right, res, removed, purged)
+}
+
+// splitValues appends the values of r ordered before pivot in the dimension dim to lo and the rest to hi.
+// Subtrees on one side of a node splitting on dim are appended without any comparisons.
+func splitValues[T Comparable[T]](d, dim int, pivot T, r *kdNode[T], cd int, lo, hi *[]T) {
+	if r == nil {
+		return
+	}
+
+	ncd := (cd + 1) % d
+	isLo := r.value.Order(pivot, dim) < 0
+	if !r.deleted {
+		if isLo {
+			*lo = append(*lo, r.value)
+		} else {
+			*hi = append(*hi, r.value)
+		}
+	}
+	if cd != dim {
+		splitValues(d, dim, pivot, r.left, ncd, lo, hi)
+		splitValues(d, dim, pivot, r.right, ncd, lo, hi)
+	} else if isLo {
+		valuesImpl(r.left, lo)
+		splitValues(d, dim, pivot, r.right, ncd, lo, hi)
+	} else {
+		splitValues(d, dim, pivot, r.left, ncd, lo, hi)
+		valuesImpl(r.right, hi)
+	}
+}
+
+func preorderTraversal[T Comparable[T]](r *kdNode[T], codec Codec[T]) ([][]byte, error) {
+	var res [][]byte
+	var err error
+	deltaCodec, isDelta := codec.(DeltaCodec[T])
+	var prev T
+	walk(r, PreOrder, func(n *kdNode[T], _ int) bool {
+		var b []byte
+		if isDelta {
+			b, err = deltaCodec.EncodeDelta(prev, n.value)
+			prev = n.value
+		} else {
+			b, err = codec.Encode(n.value)
+		}
+		res = append(res, b)
+		return err == nil
+	})
+	return res, err
 }
 
 func inorderTraversal[T Comparable[T]](r *kdNode[T], size int) []int {
@@ -331,6 +979,36 @@ func inorderTraversalImpl[T Comparable[T]](r *kdNode[T], preorderIndex, inorderI
 	inorderTraversalImpl(r.right, preorderIndex, inorderIndex, res)
 }
 
+// newSubtree builds a balanced subtree out of vs whose root splits on the dimension cd. Values that occur
+// more than once in vs are stored once.
+func newSubtree[T Comparable[T]](d int, vs []T, cd int) *kdNode[T] {
+	if len(vs) == 0 {
+		return nil
+	}
+	initialIndices := make([][]int, d)
+	for i := range initialIndices {
+		dim := (cd + i) % d
+		initialIndices[i] = internal.IotaSlice(len(vs))
+		sort.Slice(initialIndices[i], func(a, b int) bool {
+			return vs[initialIndices[i][a]].Order(vs[initialIndices[i][b]], dim) < 0
+		})
+		if i == 0 && hasDuplicates(vs, initialIndices[0], dim) {
+			return newSubtree(d, dedupe(slices.Clone(vs)), cd)
+		}
+	}
+	return insertAllNew(vs, initialIndices, cd)
+}
+
+// hasDuplicates reports whether vs, ordered by sortedIndices on dimension dim, holds a value twice.
+func hasDuplicates[T Comparable[T]](vs []T, sortedIndices []int, dim int) bool {
+	for i := 1; i < len(sortedIndices); i++ {
+		if vs[sortedIndices[i-1]].Order(vs[sortedIndices[i]], dim) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func insertAllNew[T Comparable[T]](vs []T, initialIndices [][]int, cd int) *kdNode[T] {
 	if len(initialIndices[0]) == 0 {
 		return nil
@@ -388,10 +1066,10 @@ func removeNode[T Comparable[T]](d int, value T, cd int, r *kdNode[T]) (*kdNode[
 	if r.value.Dist(value) == 0 {
 		ok = true
 		if r.right != nil {
-			r.value = *findMin(d, cd, ncd, r.right)
+			r.value = *findMin(d, cd, ncd, r.right, false)
 			r.right, ok = removeNode(d, r.value, ncd, r.right)
 		} else if r.left != nil {
-			r.value = *findMin(d, cd, ncd, r.left)
+			r.value = *findMin(d, cd, ncd, r.left, false)
 			r.right, ok = removeNode(d, r.value, ncd, r.left)
 			r.left = nil
 		} else {
@@ -405,6 +1083,48 @@ func removeNode[T Comparable[T]](d int, value T, cd int, r *kdNode[T]) (*kdNode[
 	return r, ok
 }
 
+// findPath returns the node holding value along with the path taken from r to reach it.
+func findPath[T Comparable[T]](d int, value T, r *kdNode[T]) ([]nodeInfo[T], *kdNode[T]) {
+	var path []nodeInfo[T]
+	cd := 0
+	for r != nil && value.Dist(r.value) != 0 {
+		info := nodeInfo[T]{
+			node: r,
+		}
+		if value.Order(r.value, cd) < 0 {
+			r = r.left
+			info.dir = left
+		} else {
+			r = r.right
+			info.dir = right
+		}
+		path = append(path, info)
+		cd = (cd + 1) % d
+	}
+	return path, r
+}
+
+// fitsCell reports whether n, reached through path, can hold value without breaking the split invariant
+// of either its ancestors or its children.
+func fitsCell[T Comparable[T]](d int, path []nodeInfo[T], n *kdNode[T], value T) bool {
+	for i, p := range path {
+		rel := value.Order(p.node.value, i%d)
+		if (p.dir == left && rel >= 0) || (p.dir == right && rel <= 0) {
+			return false
+		}
+	}
+
+	cd := len(path) % d
+	ncd := (cd + 1) % d
+	if n.left != nil && (*findMax(d, cd, ncd, n.left, false)).Order(value, cd) >= 0 {
+		return false
+	}
+	if n.right != nil && (*findMin(d, cd, ncd, n.right, false)).Order(value, cd) <= 0 {
+		return false
+	}
+	return true
+}
+
 func insert[T Comparable[T]](d int, value T, cd int, r *kdNode[T]) bool {
 	for value.Dist(r.value) != 0 {
 		rel := value.Order(r.value, cd)
@@ -426,10 +1146,11 @@ func insert[T Comparable[T]](d int, value T, cd int, r *kdNode[T]) bool {
 	return false
 }
 
-func nearestNeighbor[T Comparable[T]](d int, v, nn *T, cd int, r *kdNode[T]) *T {
+func nearestNeighbor[T Comparable[T]](d int, v, nn *T, cd int, r *kdNode[T], depth int, tr *queryTrace[T]) *T {
 	if r == nil {
 		return nil
 	}
+	tr.visit(r, depth)
 
 	var nextBranch, otherBranch *kdNode[T]
 	if (*v).Order(r.value, cd) < 0 /* [cd] < r.value[cd]*/ {
@@ -438,26 +1159,38 @@ func nearestNeighbor[T Comparable[T]](d int, v, nn *T, cd int, r *kdNode[T]) *T
 		nextBranch, otherBranch = r.right, r.left
 	}
 	ncd := (cd + 1) % d
-	nn = nearestNeighbor(d, v, nn, ncd, nextBranch)
-	nn = closest(v, nn, &r.value)
-
-	nearestDist := internal.Abs(distance(v, nn))
-	dist := internal.Abs((*v).DistDim(r.value, cd))
+	nn = nearestNeighbor(d, v, nn, ncd, nextBranch, depth+1, tr)
+	if !r.deleted {
+		tr.evaluate()
+		nn = closest(v, nn, &r.value)
+	}
 
-	if dist <= nearestDist {
-		nn = closest(v, nearestNeighbor(d, v, nn, ncd, otherBranch), nn)
+	if nn == nil || internal.Abs((*v).DistDim(r.value, cd)) <= internal.Abs(distance(v, nn)) {
+		nn = closest(v, nearestNeighbor(d, v, nn, ncd, otherBranch, depth+1, tr), nn)
+	} else if otherBranch != nil {
+		tr.prune()
 	}
 
 	return nn
 }
 
 func (t *KDTree[T]) KNN(value T, k int) []T {
-	if t == nil || t.root == nil || t.size < k {
+	return t.KNNWithStats(value, k, nil)
+}
+
+// KNNWithStats works like KNN and records the work done by the search in stats, which may be nil.
+func (t *KDTree[T]) KNNWithStats(value T, k int, stats *QueryStats) []T {
+	if t == nil || t.root == nil || k <= 0 || t.size < k {
 		return nil
 	}
 
+	tr := newQueryTrace[T](stats)
+	if t.periods != nil {
+		return t.periodicKNN(value, k, tr)
+	}
+
 	pqRes := NewBoundedPriorityQueue[T](k)
-	knn(k, t.dimensions, &value, &pqRes, 0, t.root)
+	knn(k, t.dimensions, &value, &pqRes, 0, t.root, 0, tr)
 
 	res := make([]T, 0, k)
 	for range k {
@@ -481,7 +1214,7 @@ type nodeInfo[T Comparable[T]] struct {
 	dir  direction
 }
 
-func knn[T Comparable[T]](k, d int, v *T, pq *BoundedPriorityQueue[T], cd int, r *kdNode[T]) {
+func knn[T Comparable[T]](k, d int, v *T, pq *BoundedPriorityQueue[T], cd int, r *kdNode[T], depth int, tr *queryTrace[T]) {
 	if r == nil {
 		return
 	}
@@ -490,6 +1223,7 @@ func knn[T Comparable[T]](k, d int, v *T, pq *BoundedPriorityQueue[T], cd int, r
 
 	var path []nodeInfo[T]
 	for r != nil {
+		tr.visit(r, depth+len(path))
 		info := nodeInfo[T]{
 			node: r,
 		}
@@ -507,20 +1241,25 @@ func knn[T Comparable[T]](k, d int, v *T, pq *BoundedPriorityQueue[T], cd int, r
 
 	ncd = (ncd - 1 + d) % d // Go back to the dimension used for splitting at the leaf node.
 	for path, cn, cDir := popLast(path); cn != nil; path, cn, cDir = popLast(path) {
-		currentDistance := (*v).Dist(cn.value)
-		internal.Push(pq, Item[T]{
-			Data:     &cn.value,
-			Priority: currentDistance,
-		})
+		if !cn.deleted {
+			tr.evaluate()
+			currentDistance := (*v).Dist(cn.value)
+			internal.Push(pq, Item[T]{
+				Data:     &cn.value,
+				Priority: currentDistance,
+			})
+		}
 
+		var next *kdNode[T]
+		if cDir == left {
+			next = cn.right
+		} else {
+			next = cn.left
+		}
 		if pq.Len() < pq.Capacity() || (*v).DistDim(cn.value, ncd) < getFarthestDistance(pq) {
-			var next *kdNode[T]
-			if cDir == left {
-				next = cn.right
-			} else {
-				next = cn.left
-			}
-			knn(k, d, v, pq, (ncd+1)%d, next)
+			knn(k, d, v, pq, (ncd+1)%d, next, depth+len(path)+1, tr)
+		} else if next != nil {
+			tr.prune()
 		}
 		ncd = (ncd - 1 + d) % d
 	}
@@ -539,65 +1278,40 @@ func popLast[T Comparable[T]](arr []nodeInfo[T]) ([]nodeInfo[T], *kdNode[T], dir
 	return arr[:li], arr[li].node, arr[li].dir
 }
 
-func findMin[T Comparable[T]](d, tcd, cd int, r *kdNode[T]) *T {
+// findMin returns the minimum value in the dimension tcd of the subtree r, whose root splits on the
+// dimension cd. Values of deleted nodes are ignored when skipDeleted is set.
+func findMin[T Comparable[T]](d, tcd, cd int, r *kdNode[T], skipDeleted bool) *T {
 	if r == nil {
 		return nil
 	}
 
-	var lMin *T
-	var rMin *T
 	ncd := (cd + 1) % d
-	lMin = findMin(d, tcd, ncd, r.left)
-	if tcd != cd {
-		rMin = findMin(d, tcd, ncd, r.right)
-	}
-	if lMin == nil && rMin == nil {
-		return &r.value
-	} else if lMin == nil {
-		if (*rMin).Order(r.value, tcd) < 0 {
-			return rMin
-		}
-		return &r.value
-	} else if rMin == nil {
-		if (*lMin).Order(r.value, tcd) < 0 {
-			return lMin
-		}
-		return &r.value
-	} else {
-		// temp := []*T{lMin, rMin, &r.value}
-		// sort.Slice(temp, func(i, j int) bool {
-		// 	return (*temp[i]).Order(*temp[j], tcd) < 0
-		// })
-		// return temp[0]
-		return min(lMin, min(rMin, &r.value, tcd), tcd)
+	res := findMin(d, tcd, ncd, r.left, skipDeleted)
+	if !skipDeleted || !r.deleted {
+		res = min(res, &r.value, tcd)
+	}
+	// The right subtree only needs to be searched if it is not split on the target dimension, or if
+	// everything before it has been deleted.
+	if tcd != cd || res == nil {
+		res = min(res, findMin(d, tcd, ncd, r.right, skipDeleted), tcd)
 	}
+	return res
 }
 
-func findMax[T Comparable[T]](d, tcd, cd int, r *kdNode[T]) *T {
+// findMax returns the maximum value in the dimension tcd of the subtree r, whose root splits on the
+// dimension cd. Values of deleted nodes are ignored when skipDeleted is set.
+func findMax[T Comparable[T]](d, tcd, cd int, r *kdNode[T], skipDeleted bool) *T {
 	if r == nil {
 		return nil
 	}
 
-	var lMax *T
-	var rMax *T
 	ncd := (cd + 1) % d
-	rMax = findMax(d, tcd, ncd, r.right)
-	if tcd != cd {
-		lMax = findMax(d, tcd, ncd, r.left)
-	}
-	if lMax == nil && rMax == nil {
-		return &r.value
-	} else if lMax == nil {
-		if (*rMax).Order(r.value, tcd) > 0 {
-			return rMax
-		}
-		return &r.value
-	} else if rMax == nil {
-		if (*lMax).Order(r.value, tcd) > 0 {
-			return lMax
-		}
-		return &r.value
-	} else {
-		return max(lMax, max(rMax, &r.value, tcd), tcd)
+	res := findMax(d, tcd, ncd, r.right, skipDeleted)
+	if !skipDeleted || !r.deleted {
+		res = max(res, &r.value, tcd)
 	}
+	if tcd != cd || res == nil {
+		res = max(res, findMax(d, tcd, ncd, r.left, skipDeleted), tcd)
+	}
+	return res
 }
@@ -8,6 +8,7 @@ func OldKDTreeWithValues[T Comparable[T]](d int, vs []T) *KDTree[T] {
 		dimensions: d,
 		root:       root,
 		isSetup:    true,
+		size:       countNodes(root),
 	}
 }
 
@@ -0,0 +1,64 @@
+package kdtree
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// codecs maps the type of the values in a tree to the Codec used to encode and decode them.
+var codecs sync.Map
+
+// RegisterCodec registers the codec used by MarshalBinary, UnmarshalBinary, GobEncode and GobDecode for
+// trees holding values of type T.
+func RegisterCodec[T Comparable[T]](codec Codec[T]) {
+	codecs.Store(reflect.TypeFor[T](), codec)
+}
+
+func registeredCodec[T Comparable[T]]() (Codec[T], error) {
+	codec, ok := codecs.Load(reflect.TypeFor[T]())
+	if !ok {
+		return nil, fmt.Errorf("no codec registered for %v, use RegisterCodec", reflect.TypeFor[T]())
+	}
+	return codec.(Codec[T]), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the same format as EncodeWith, encoding the
+// values with the codec registered for T by RegisterCodec.
+func (t *KDTree[T]) MarshalBinary() ([]byte, error) {
+	codec, err := registeredCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	return t.EncodeWith(codec)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the values with the codec registered
+// for T by RegisterCodec. Like NewKDTreeFromBytes, the decoded tree is balanced.
+func (t *KDTree[T]) UnmarshalBinary(data []byte) error {
+	codec, err := registeredCodec[T]()
+	if err != nil {
+		return err
+	}
+	res, err := NewKDTreeFromBytesWith(data, codec)
+	if err != nil {
+		return err
+	}
+	res.lazyDelete = t.lazyDelete
+	res.compactRatio = t.compactRatio
+	if len(t.periods) == res.dimensions {
+		res.periods = t.periods
+	}
+	*t = *res
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the same format as MarshalBinary.
+func (t *KDTree[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder using the same format as UnmarshalBinary.
+func (t *KDTree[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
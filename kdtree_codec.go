@@ -0,0 +1,110 @@
+package kdtree
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec converts the values of a tree to and from bytes when the tree is encoded. Values only need a
+// Codec if the tree holding them is encoded.
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// FixedSizeCodec is implemented by codecs that encode every value into the same number of bytes. Trees
+// encoded with such a codec store all values back to back in a single packed array instead of one table
+// per value.
+type FixedSizeCodec[T any] interface {
+	Codec[T]
+	Size() int
+}
+
+// DeltaCodec is implemented by codecs that can encode a value relative to the value stored before it, so
+// that values close to each other take up few bytes. Trees encoded with such a codec store all values back
+// to back in a single packed array, each one encoded relative to its predecessor in preorder and the first
+// one relative to the zero value.
+type DeltaCodec[T any] interface {
+	Codec[T]
+	EncodeDelta(prev, value T) ([]byte, error)
+	DecodeDelta(prev T, data []byte) (T, error)
+}
+
+// packDeltaItems stores items back to back, each prefixed by its length as an unsigned varint.
+func packDeltaItems(items [][]byte) []byte {
+	var b []byte
+	for _, item := range items {
+		b = binary.AppendUvarint(b, uint64(len(item)))
+		b = append(b, item...)
+	}
+	return b
+}
+
+// unpackDeltaItems splits the items stored by packDeltaItems, without copying them.
+func unpackDeltaItems(b []byte) ([][]byte, error) {
+	var items [][]byte
+	for len(b) > 0 {
+		n, size := binary.Uvarint(b)
+		if size <= 0 || n > uint64(len(b)-size) {
+			return nil, fmt.Errorf("%w: delta encoded item %d is truncated", ErrInvalidEncoding, len(items))
+		}
+		items = append(items, b[size:size+int(n)])
+		b = b[size+int(n):]
+	}
+	return items, nil
+}
+
+// FuncCodec adapts a pair of functions to a Codec.
+type FuncCodec[T any] struct {
+	EncodeFunc func(T) ([]byte, error)
+	DecodeFunc func([]byte) (T, error)
+}
+
+func (c FuncCodec[T]) Encode(value T) ([]byte, error) {
+	if c.EncodeFunc == nil {
+		return nil, fmt.Errorf("codec for %v has no EncodeFunc", reflect.TypeFor[T]())
+	}
+	return c.EncodeFunc(value)
+}
+
+func (c FuncCodec[T]) Decode(data []byte) (T, error) {
+	if c.DecodeFunc == nil {
+		return *new(T), fmt.Errorf("codec for %v has no DecodeFunc", reflect.TypeFor[T]())
+	}
+	return c.DecodeFunc(data)
+}
+
+// JSONCodec encodes values with encoding/json.
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+func (JSONCodec[T]) Name() string {
+	return "json"
+}
+
+// methodCodec encodes values with their own Encode() []byte method, which is how values used to be
+// encoded before Codec was introduced.
+type methodCodec[T any] struct{}
+
+func (methodCodec[T]) Encode(value T) ([]byte, error) {
+	e, ok := any(value).(interface{ Encode() []byte })
+	if !ok {
+		return nil, fmt.Errorf("%v has no Encode() []byte method, use EncodeWith", reflect.TypeFor[T]())
+	}
+	return e.Encode(), nil
+}
+
+func (methodCodec[T]) Decode(data []byte) (T, error) {
+	return *new(T), fmt.Errorf("%v can not be decoded without a codec", reflect.TypeFor[T]())
+}
@@ -0,0 +1,122 @@
+package kdtree
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compressor compresses the encoded tree stored in a container. Its name is recorded in the container
+// header, so that UnmarshalContainer can find the compressor to decompress the tree with.
+type Compressor interface {
+	Name() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// compressors maps the names of the compressors registered by RegisterCompressor to them.
+var compressors sync.Map
+
+// RegisterCompressor makes compressor available to UnmarshalContainer and LoadFile under its name.
+// GzipCompressor and FlateCompressor do not need to be registered.
+func RegisterCompressor(compressor Compressor) {
+	compressors.Store(compressor.Name(), compressor)
+}
+
+func compressorByName(name string) (Compressor, error) {
+	if c, ok := compressors.Load(name); ok {
+		return c.(Compressor), nil
+	}
+	switch name {
+	case GzipCompressor{}.Name():
+		return GzipCompressor{}, nil
+	case FlateCompressor{}.Name():
+		return FlateCompressor{}, nil
+	}
+	return nil, fmt.Errorf("no compressor registered for %q, use RegisterCompressor", name)
+}
+
+func compress(compressor Compressor, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := compressor.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decompresses data, failing once the decompressed data grows beyond maxBytes so that a small
+// malicious container can not exhaust memory. A maxBytes of 0 disables the limit.
+func decompress(compressor Compressor, data []byte, maxBytes int) ([]byte, error) {
+	r, err := compressor.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContainer, err)
+	}
+	defer r.Close()
+	var src io.Reader = r
+	if maxBytes > 0 {
+		src = io.LimitReader(r, int64(maxBytes)+1)
+	}
+	res, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContainer, err)
+	}
+	if maxBytes > 0 && len(res) > maxBytes {
+		return nil, fmt.Errorf("%w: decompressed tree exceeds the limit of %d bytes", ErrInvalidContainer, maxBytes)
+	}
+	return res, nil
+}
+
+// GzipCompressor compresses with gzip at Level, one of the levels of compress/gzip. A Level of 0 uses
+// gzip.DefaultCompression rather than gzip.NoCompression.
+type GzipCompressor struct {
+	Level int
+}
+
+func (GzipCompressor) Name() string {
+	return "gzip"
+}
+
+func (c GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+func (GzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// FlateCompressor compresses with raw DEFLATE at Level, one of the levels of compress/flate. A Level of 0
+// uses flate.DefaultCompression rather than flate.NoCompression.
+type FlateCompressor struct {
+	Level int
+}
+
+func (FlateCompressor) Name() string {
+	return "flate"
+}
+
+func (c FlateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return flate.NewWriter(w, level)
+}
+
+func (FlateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
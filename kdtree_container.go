@@ -0,0 +1,298 @@
+package kdtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// containerMagic starts every file written by SaveFile.
+var containerMagic = [4]byte{'K', 'D', 'T', 'C'}
+
+// containerVersion is the version of the containers written by MarshalContainer. Version 1 containers,
+// which have no compression field, can still be read.
+const containerVersion uint16 = 2
+
+var ErrInvalidContainer = fmt.Errorf("container is invalid")
+var ErrChecksumMismatch = fmt.Errorf("container checksum does not match its contents")
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// NamedCodec is implemented by codecs that have a name. The name of the codec used to encode a tree is
+// recorded in the container header, so that readers can tell how the values were encoded.
+type NamedCodec interface {
+	Name() string
+}
+
+// ContainerOptions configures the container written by MarshalContainer and SaveFile.
+type ContainerOptions struct {
+	// Metadata is stored in the header as is.
+	Metadata map[string]string
+	// Created is recorded as the creation time of the container. The current time is used when it is zero.
+	Created time.Time
+	// Compressor compresses the encoded tree when it is set.
+	Compressor Compressor
+}
+
+// ContainerHeader describes the tree stored in a container.
+type ContainerHeader struct {
+	Version int
+	Codec   string
+	// Compression is the name of the compressor of the encoded tree, or empty if it is not compressed.
+	Compression string
+	Dimensions  int
+	Count       int
+	Created     time.Time
+	Metadata    map[string]string
+}
+
+// codecName returns the name of codec recorded in container headers.
+func codecName[T any](codec Codec[T]) string {
+	if named, ok := codec.(NamedCodec); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", codec)
+}
+
+// MarshalContainer encodes the tree with codec and wraps it in a container made up of a header
+// describing the tree, the encoded tree and a CRC-32C checksum of both.
+//
+// All integers are little-endian. The header holds the magic bytes "KDTC", the container version
+// (uint16), the number of dimensions (uint32), the number of values (uint64), the creation time in
+// nanoseconds since the Unix epoch (int64), the codec name (uint16 length and bytes), the compressor name
+// (uint16 length and bytes, empty if the encoded tree is not compressed), the metadata
+// (uint32 count, then a uint16 length and bytes per key and a uint32 length and bytes per value) and the
+// length of the encoded tree (uint64).
+func (t *KDTree[T]) MarshalContainer(codec Codec[T], opts ContainerOptions) ([]byte, error) {
+	payload, err := t.EncodeWith(codec)
+	if err != nil {
+		return nil, err
+	}
+	created := opts.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	name := codecName(codec)
+	if len(name) > 1<<16-1 {
+		return nil, fmt.Errorf("codec name of %d bytes is too long", len(name))
+	}
+	compression := ""
+	if opts.Compressor != nil {
+		compression = opts.Compressor.Name()
+		if compression == "" || len(compression) > 1<<16-1 {
+			return nil, fmt.Errorf("compressor name must have between 1 and %d bytes", 1<<16-1)
+		}
+		if payload, err = compress(opts.Compressor, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	b := append([]byte(nil), containerMagic[:]...)
+	b = binary.LittleEndian.AppendUint16(b, containerVersion)
+	b = binary.LittleEndian.AppendUint32(b, uint32(t.dimensions))
+	b = binary.LittleEndian.AppendUint64(b, uint64(t.size))
+	b = binary.LittleEndian.AppendUint64(b, uint64(created.UnixNano()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(name)))
+	b = append(b, name...)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(compression)))
+	b = append(b, compression...)
+
+	keys := make([]string, 0, len(opts.Metadata))
+	for k := range opts.Metadata {
+		if len(k) > 1<<16-1 {
+			return nil, fmt.Errorf("metadata key of %d bytes is too long", len(k))
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(keys)))
+	for _, k := range keys {
+		v := opts.Metadata[k]
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(k)))
+		b = append(b, k...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+		b = append(b, v...)
+	}
+
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(payload)))
+	b = append(b, payload...)
+	return binary.LittleEndian.AppendUint32(b, crc32.Checksum(b, crc32c)), nil
+}
+
+// UnmarshalContainer decodes a tree from a container written by MarshalContainer, using codec to decode
+// its values. It returns an error wrapping ErrInvalidContainer if the container is truncated or
+// malformed, ErrChecksumMismatch if it was corrupted and an error if the codec name in the header is not
+// the name of codec.
+func UnmarshalContainer[T Comparable[T]](data []byte, codec Codec[T]) (*KDTree[T], ContainerHeader, error) {
+	return UnmarshalContainerWithOptions(data, codec, DefaultDecodeOptions)
+}
+
+// UnmarshalContainerWithOptions is UnmarshalContainer with the limits of opts. The checksum does not
+// authenticate the container, so callers reading containers from untrusted sources should set
+// opts.Dimensions: containers whose header has another number of dimensions are rejected with an error
+// wrapping ErrDimensionMismatch.
+func UnmarshalContainerWithOptions[T Comparable[T]](data []byte, codec Codec[T], opts DecodeOptions) (*KDTree[T], ContainerHeader, error) {
+	header, payload, err := readContainer(data)
+	if err != nil {
+		return nil, header, err
+	}
+	if name := codecName(codec); header.Codec != name {
+		return nil, header, fmt.Errorf("values were encoded with codec %q, not %q", header.Codec, name)
+	}
+	if header.Compression != "" {
+		compressor, err := compressorByName(header.Compression)
+		if err != nil {
+			return nil, header, err
+		}
+		if payload, err = decompress(compressor, payload, opts.MaxBytes); err != nil {
+			return nil, header, err
+		}
+	}
+	if opts.Dimensions > 0 && header.Dimensions != opts.Dimensions {
+		return nil, header, fmt.Errorf("%w: container has %d dimensions instead of %d",
+			ErrDimensionMismatch, header.Dimensions, opts.Dimensions)
+	}
+	opts.Dimensions = header.Dimensions
+	t, err := NewKDTreeFromBytesWithOptions(payload, codec, opts)
+	if err != nil {
+		return nil, header, err
+	}
+	if t.size != header.Count {
+		return nil, header, fmt.Errorf("%w: header counts %d values but the tree holds %d", ErrInvalidContainer, header.Count, t.size)
+	}
+	return t, header, nil
+}
+
+// ReadContainerHeader returns the header of a container written by MarshalContainer after verifying its
+// checksum, without decoding the tree.
+func ReadContainerHeader(data []byte) (ContainerHeader, error) {
+	header, _, err := readContainer(data)
+	return header, err
+}
+
+// SaveFile writes the tree to the file at path in the container format of MarshalContainer. The file is
+// replaced atomically, so readers never see a partially written tree.
+func (t *KDTree[T]) SaveFile(path string, codec Codec[T], opts ContainerOptions) error {
+	b, err := t.MarshalContainer(codec, opts)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	// CreateTemp creates files only readable by their owner.
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile reads a tree saved by SaveFile, using codec to decode its values.
+func LoadFile[T Comparable[T]](path string, codec Codec[T]) (*KDTree[T], ContainerHeader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ContainerHeader{}, err
+	}
+	return UnmarshalContainer(b, codec)
+}
+
+// LoadFileWithOptions is LoadFile with the limits of opts, see UnmarshalContainerWithOptions.
+func LoadFileWithOptions[T Comparable[T]](path string, codec Codec[T], opts DecodeOptions) (*KDTree[T], ContainerHeader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ContainerHeader{}, err
+	}
+	return UnmarshalContainerWithOptions(b, codec, opts)
+}
+
+// containerReader reads the fields of a container, remembering whether it ran out of bytes.
+type containerReader struct {
+	b         []byte
+	truncated bool
+}
+
+func (r *containerReader) next(n int) []byte {
+	if r.truncated || n > len(r.b) {
+		r.truncated = true
+		return make([]byte, n)
+	}
+	res := r.b[:n]
+	r.b = r.b[n:]
+	return res
+}
+
+func (r *containerReader) uint16() int {
+	return int(binary.LittleEndian.Uint16(r.next(2)))
+}
+
+func (r *containerReader) uint32() int {
+	return int(binary.LittleEndian.Uint32(r.next(4)))
+}
+
+func (r *containerReader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func (r *containerReader) bytes(n uint64) []byte {
+	if n > uint64(len(r.b)) {
+		r.truncated = true
+		return nil
+	}
+	return r.next(int(n))
+}
+
+func readContainer(data []byte) (ContainerHeader, []byte, error) {
+	var header ContainerHeader
+	r := &containerReader{b: data}
+	if magic := r.next(len(containerMagic)); r.truncated || [4]byte(magic) != containerMagic {
+		return header, nil, fmt.Errorf("%w: missing magic bytes", ErrInvalidContainer)
+	}
+	header.Version = r.uint16()
+	if !r.truncated && (header.Version < 1 || header.Version > int(containerVersion)) {
+		return header, nil, fmt.Errorf("%w: unsupported container version %d", ErrInvalidContainer, header.Version)
+	}
+	header.Dimensions = r.uint32()
+	header.Count = int(r.uint64())
+	header.Created = time.Unix(0, int64(r.uint64())).UTC()
+	header.Codec = string(r.bytes(uint64(r.uint16())))
+	if header.Version >= 2 {
+		header.Compression = string(r.bytes(uint64(r.uint16())))
+	}
+	if n := r.uint32(); n > 0 && !r.truncated {
+		header.Metadata = make(map[string]string)
+		for i := 0; i < n && !r.truncated; i++ {
+			k := string(r.bytes(uint64(r.uint16())))
+			header.Metadata[k] = string(r.bytes(uint64(r.uint32())))
+		}
+	}
+	payload := r.bytes(r.uint64())
+	checksum := r.next(4)
+	if r.truncated {
+		return header, nil, fmt.Errorf("%w: container is truncated", ErrInvalidContainer)
+	}
+	if len(r.b) != 0 {
+		return header, nil, fmt.Errorf("%w: %d unexpected bytes after the checksum", ErrInvalidContainer, len(r.b))
+	}
+	if crc32.Checksum(data[:len(data)-4], crc32c) != binary.LittleEndian.Uint32(checksum) {
+		return header, nil, ErrChecksumMismatch
+	}
+	return header, payload, nil
+}
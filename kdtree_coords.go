@@ -0,0 +1,121 @@
+package kdtree
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// Coords derives a Comparable implementation for values of type T from their coordinates. Create it with
+// ByCoords and wrap values with Point before inserting them into a tree.
+type Coords[T any] struct {
+	dims  int
+	coord func(T, int) float64
+}
+
+// ByCoords returns a Coords reading the dims coordinates of each value with coord. Values are compared by
+// their super key, so values with different coordinates never compare as equal. Values with the same
+// coordinates are the same point to the tree.
+func ByCoords[T any](dims int, coord func(T, int) float64) Coords[T] {
+	return Coords[T]{dims: dims, coord: coord}
+}
+
+// CoordPoint is a value wrapped with the coordinates read from it by Coords.
+type CoordPoint[T any] struct {
+	Value  T
+	coords []float64
+}
+
+// Point wraps v so that it can be stored in a tree.
+func (c Coords[T]) Point(v T) CoordPoint[T] {
+	coords := make([]float64, c.dims)
+	for dim := range coords {
+		coords[dim] = c.coord(v, dim)
+	}
+	return CoordPoint[T]{Value: v, coords: coords}
+}
+
+// Points wraps every value of vs.
+func (c Coords[T]) Points(vs []T) []CoordPoint[T] {
+	res := make([]CoordPoint[T], len(vs))
+	for i, v := range vs {
+		res[i] = c.Point(v)
+	}
+	return res
+}
+
+// Codec returns a Codec for wrapped values that encodes the values with codec and reads their coordinates
+// again when decoding them.
+func (c Coords[T]) Codec(codec Codec[T]) Codec[CoordPoint[T]] {
+	return FuncCodec[CoordPoint[T]]{
+		EncodeFunc: func(p CoordPoint[T]) ([]byte, error) {
+			return codec.Encode(p.Value)
+		},
+		DecodeFunc: func(data []byte) (CoordPoint[T], error) {
+			v, err := codec.Decode(data)
+			if err != nil {
+				return CoordPoint[T]{}, err
+			}
+			return c.Point(v), nil
+		},
+	}
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs CoordPoint[T]) Coord(dim int) float64 {
+	return lhs.coords[dim]
+}
+
+// Dimensions returns the number of coordinates of the point.
+func (lhs CoordPoint[T]) Dimensions() int {
+	return len(lhs.coords)
+}
+
+// Order compares the super keys of the points, starting from dimension dim and continuing cyclically
+// through the others to break ties.
+func (lhs CoordPoint[T]) Order(rhs CoordPoint[T], dim int) int {
+	for range lhs.coords {
+		if c := cmp.Compare(lhs.coords[dim], rhs.coords[dim]); c != 0 {
+			return c
+		}
+		dim = (dim + 1) % len(lhs.coords)
+	}
+	return 0
+}
+
+// Dist returns the squared euclidean distance between the points as a FloatDistKey.
+func (lhs CoordPoint[T]) Dist(rhs CoordPoint[T]) int {
+	sum := 0.0
+	for dim := range lhs.coords {
+		d := lhs.coords[dim] - rhs.coords[dim]
+		sum += d * d
+	}
+	return FloatDistKey(sum)
+}
+
+func (lhs CoordPoint[T]) DistDim(rhs CoordPoint[T], dim int) int {
+	d := lhs.coords[dim] - rhs.coords[dim]
+	return FloatDistKey(d * d)
+}
+
+// Translate shifts the coordinates of the point, leaving the wrapped value untouched, so that trees of
+// wrapped values can be periodic.
+func (lhs CoordPoint[T]) Translate(dim int, delta float64) CoordPoint[T] {
+	coords := make([]float64, len(lhs.coords))
+	copy(coords, lhs.coords)
+	coords[dim] += delta
+	return CoordPoint[T]{Value: lhs.Value, coords: coords}
+}
+
+func (lhs CoordPoint[T]) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v [", lhs.Value)
+	for dim, c := range lhs.coords {
+		if dim > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%g", c)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
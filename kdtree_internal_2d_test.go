@@ -422,3 +422,171 @@ func Test2DInsertDuplicateNode(t *testing.T) {
 		}
 	}
 }
+
+func Test2DUpdate(t *testing.T) {
+	newTree := func() *KDTree[types.Tensor2D] {
+		treeNodes := NewKDNode(types.Tensor2D{25, 50}).
+			SetLeft(
+				NewKDNode(types.Tensor2D{3, 25}),
+			).
+			SetRight(
+				NewKDNode(types.Tensor2D{40, 60}).
+					SetLeft(
+						NewKDNode(types.Tensor2D{30, 40}),
+					),
+			)
+		return NewTestKDTree(2, treeNodes)
+	}
+
+	testTable := []struct {
+		name          string
+		oldValue      types.Tensor2D
+		newValue      types.Tensor2D
+		expectedFound bool
+		expected      *KDTree[types.Tensor2D]
+	}{
+		{
+			name:          "Small move of a leaf node is done in place",
+			oldValue:      types.Tensor2D{30, 40},
+			newValue:      types.Tensor2D{32, 45},
+			expectedFound: true,
+			expected: NewTestKDTree(2, NewKDNode(types.Tensor2D{25, 50}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{3, 25}),
+				).
+				SetRight(
+					NewKDNode(types.Tensor2D{40, 60}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{32, 45}),
+						),
+				)),
+		},
+		{
+			name:          "Small move of an inner node is done in place",
+			oldValue:      types.Tensor2D{40, 60},
+			newValue:      types.Tensor2D{45, 55},
+			expectedFound: true,
+			expected: NewTestKDTree(2, NewKDNode(types.Tensor2D{25, 50}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{3, 25}),
+				).
+				SetRight(
+					NewKDNode(types.Tensor2D{45, 55}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{30, 40}),
+						),
+				)),
+		},
+		{
+			name:          "Move across the root's splitting line falls back to remove and insert",
+			oldValue:      types.Tensor2D{30, 40},
+			newValue:      types.Tensor2D{10, 40},
+			expectedFound: true,
+			expected: NewTestKDTree(2, NewKDNode(types.Tensor2D{25, 50}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{3, 25}).
+						SetRight(
+							NewKDNode(types.Tensor2D{10, 40}),
+						),
+				).
+				SetRight(
+					NewKDNode(types.Tensor2D{40, 60}),
+				)),
+		},
+		{
+			name:          "Value not in the tree",
+			oldValue:      types.Tensor2D{1, 1},
+			newValue:      types.Tensor2D{2, 2},
+			expectedFound: false,
+			expected:      newTree(),
+		},
+	}
+	for _, v := range testTable {
+		t.Run(v.name, func(t *testing.T) {
+			tree := newTree()
+			if ok := tree.Update(v.oldValue, v.newValue); ok != v.expectedFound {
+				t.Fatalf("Expected Update to return %v, got %v", v.expectedFound, ok)
+			}
+			if !IdenticalTrees(tree, v.expected) {
+				t.Fatalf("Tree does not match expected tree structure\nExpected:\n%s\nGot:\n%s", v.expected, tree)
+			}
+		})
+	}
+}
+
+func Test2DLazyDeleteCompaction(t *testing.T) {
+	treeNodes := NewKDNode(types.Tensor2D{25, 50}).
+		SetLeft(
+			NewKDNode(types.Tensor2D{3, 25}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{20, 15}),
+				),
+		).
+		SetRight(
+			NewKDNode(types.Tensor2D{40, 60}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{30, 40}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{28, 17}),
+						),
+				),
+		)
+	tree := NewTestKDTree(2, treeNodes)
+	tree.SetLazyDelete(true, 0.4)
+
+	// Removing the first two nodes only marks them as deleted.
+	expTreeNodes1 := NewKDNode(types.Tensor2D{25, 50}).
+		SetLeft(
+			NewKDNode(types.Tensor2D{3, 25}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{20, 15}),
+				),
+		).
+		SetRight(
+			NewKDNode(types.Tensor2D{40, 60}).
+				SetLeft(
+					NewKDNode(types.Tensor2D{30, 40}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{28, 17}),
+						),
+				),
+		)
+	// Removing the third node pushes the deleted nodes over the threshold and compacts the tree.
+	expTreeNodes2 := NewKDNode(types.Tensor2D{20, 15}).
+		SetLeft(
+			NewKDNode(types.Tensor2D{3, 25}),
+		).
+		SetRight(
+			NewKDNode(types.Tensor2D{28, 17}),
+		)
+	testTable := []struct {
+		input              types.Tensor2D
+		expected           *KDTree[types.Tensor2D]
+		expectedTombstones int
+	}{
+		{
+			input:              types.Tensor2D{25, 50},
+			expected:           NewTestKDTree(2, expTreeNodes1),
+			expectedTombstones: 1,
+		},
+		{
+			input:              types.Tensor2D{40, 60},
+			expected:           NewTestKDTree(2, expTreeNodes1),
+			expectedTombstones: 2,
+		},
+		{
+			input:              types.Tensor2D{30, 40},
+			expected:           NewTestKDTree(2, expTreeNodes2),
+			expectedTombstones: 0,
+		},
+	}
+	for _, v := range testTable {
+		ok := tree.Remove(v.input)
+		if !ok || !IdenticalTrees(tree, v.expected) {
+			t.Fatalf("Tree does not match expected tree structure\nExpected:\n%s\nGot:\n%s", v.expected, tree)
+		}
+		if tree.tombstones != v.expectedTombstones {
+			t.Fatalf("Expected %d deleted nodes, got %d", v.expectedTombstones, tree.tombstones)
+		}
+	}
+}
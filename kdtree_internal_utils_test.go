@@ -10,13 +10,6 @@ func NewTestKDTree[T Comparable[T]](d int, r *kdNode[T]) *KDTree[T] {
 	}
 }
 
-func countNodes[T Comparable[T]](r *kdNode[T]) int {
-	if r == nil {
-		return 0
-	}
-	return 1 + countNodes(r.left) + countNodes(r.right)
-}
-
 func (n *kdNode[T]) SetLeft(nn *kdNode[T]) *kdNode[T] {
 	n.left = nn
 	return n
@@ -0,0 +1,82 @@
+package kdtree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	types "github.com/rishitc/go-kd-tree/internal/types"
+)
+
+func TestValidate(t *testing.T) {
+	validTree := func() *kdNode[types.Tensor2D] {
+		return NewKDNode(types.Tensor2D{25, 50}).
+			SetLeft(
+				NewKDNode(types.Tensor2D{3, 25}),
+			).
+			SetRight(
+				NewKDNode(types.Tensor2D{40, 60}).
+					SetLeft(
+						NewKDNode(types.Tensor2D{30, 40}),
+					),
+			)
+	}
+	sizeMismatch := NewTestKDTree(2, validTree())
+	sizeMismatch.size++
+
+	testTable := []struct {
+		name        string
+		input       *KDTree[types.Tensor2D]
+		expectedErr string
+	}{
+		{
+			name:  "Valid tree",
+			input: NewTestKDTree(2, validTree()),
+		},
+		{
+			name:  "Empty tree",
+			input: NewTestKDTree[types.Tensor2D](2, nil),
+		},
+		{
+			name: "Node on the wrong side of the root",
+			input: NewTestKDTree(2, NewKDNode(types.Tensor2D{25, 50}).
+				SetRight(
+					NewKDNode(types.Tensor2D{40, 60}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{20, 40}),
+						),
+				)),
+			expectedErr: "value [20, 40] at root.right.left is before [25, 50] in dimension 0",
+		},
+		{
+			name:        "Size does not match the nodes",
+			input:       sizeMismatch,
+			expectedErr: "tree size is 5 but it holds 4 values",
+		},
+		{
+			name: "Duplicate values",
+			input: NewTestKDTree(2, NewKDNode(types.Tensor2D{25, 50}).
+				SetRight(
+					NewKDNode(types.Tensor2D{40, 60}).
+						SetLeft(
+							NewKDNode(types.Tensor2D{25, 50}),
+						),
+				)),
+			expectedErr: "value [25, 50] is stored at root and root.right.left",
+		},
+	}
+	for _, v := range testTable {
+		t.Run(v.name, func(t *testing.T) {
+			err := v.input.Validate()
+			if v.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Expected a valid tree, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidTree) || !strings.Contains(err.Error(), v.expectedErr) {
+				t.Fatalf("Expected error containing %q, got %v", v.expectedErr, err)
+			}
+		})
+	}
+}
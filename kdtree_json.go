@@ -0,0 +1,214 @@
+package kdtree
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONOptions configures MarshalJSONWith and UnmarshalJSONWith.
+type JSONOptions[T Comparable[T]] struct {
+	// EncodeItem and DecodeItem convert a single value to and from JSON. They default to encoding/json.
+	EncodeItem func(T) ([]byte, error)
+	DecodeItem func([]byte) (T, error)
+	// PointsOnly only emits the values of the tree instead of its structure. A tree read back from this
+	// form is balanced.
+	PointsOnly bool
+	// Dimensions makes UnmarshalJSONWith reject trees with any other number of dimensions. When it is 0,
+	// trees unmarshalled into a tree created by New or NewKDTreeWithValues must have as many dimensions
+	// as that tree.
+	Dimensions int
+}
+
+type jsonTree struct {
+	Dimensions int               `json:"dimensions"`
+	Root       *jsonNode         `json:"root,omitempty"`
+	Points     []json.RawMessage `json:"points,omitempty"`
+}
+
+type jsonNode struct {
+	Value     json.RawMessage `json:"value"`
+	Dimension int             `json:"dimension"`
+	Deleted   bool            `json:"deleted,omitempty"`
+	Left      *jsonNode       `json:"left"`
+	Right     *jsonNode       `json:"right"`
+}
+
+// MarshalJSON encodes the structure of the tree as nested JSON objects, using encoding/json for the
+// values.
+func (t *KDTree[T]) MarshalJSON() ([]byte, error) {
+	return t.MarshalJSONWith(JSONOptions[T]{})
+}
+
+// UnmarshalJSON reconstructs exactly the tree encoded by MarshalJSON.
+func (t *KDTree[T]) UnmarshalJSON(data []byte) error {
+	return t.UnmarshalJSONWith(data, JSONOptions[T]{})
+}
+
+// MarshalJSONWith works like MarshalJSON, with opts choosing how values are encoded and whether the
+// structure of the tree is kept.
+func (t *KDTree[T]) MarshalJSONWith(opts JSONOptions[T]) ([]byte, error) {
+	if !t.isSetup {
+		return nil, ErrTreeNotSetup
+	}
+	encodeItem := opts.EncodeItem
+	if encodeItem == nil {
+		encodeItem = func(v T) ([]byte, error) {
+			return json.Marshal(v)
+		}
+	}
+
+	jt := jsonTree{
+		Dimensions: t.dimensions,
+	}
+	if opts.PointsOnly {
+		jt.Points = make([]json.RawMessage, 0, t.size)
+		for _, v := range t.Values() {
+			b, err := encodeItem(v)
+			if err != nil {
+				return nil, err
+			}
+			jt.Points = append(jt.Points, b)
+		}
+	} else {
+		var err error
+		if jt.Root, err = toJSONNode(t.dimensions, t.root, 0, encodeItem); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(jt)
+}
+
+// UnmarshalJSONWith replaces the contents of the tree with the tree encoded in data by MarshalJSONWith.
+// Trees encoded with their structure are reconstructed exactly and checked with Validate, except that nodes
+// marked as deleted are compacted away unless the tree deletes lazily.
+func (t *KDTree[T]) UnmarshalJSONWith(data []byte, opts JSONOptions[T]) error {
+	decodeItem := opts.DecodeItem
+	if decodeItem == nil {
+		decodeItem = func(b []byte) (T, error) {
+			var v T
+			err := json.Unmarshal(b, &v)
+			return v, err
+		}
+	}
+
+	var jt jsonTree
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return err
+	}
+	if jt.Dimensions <= 0 {
+		return fmt.Errorf("%w: tree has %d dimensions", ErrInvalidDimension, jt.Dimensions)
+	}
+	expected := opts.Dimensions
+	if expected == 0 && t.isSetup {
+		expected = t.dimensions
+	}
+	if expected > 0 && jt.Dimensions != expected {
+		return fmt.Errorf("%w: tree has %d dimensions instead of %d", ErrDimensionMismatch, jt.Dimensions, expected)
+	}
+
+	res := &KDTree[T]{
+		dimensions:   jt.Dimensions,
+		isSetup:      true,
+		lazyDelete:   t.lazyDelete,
+		compactRatio: t.compactRatio,
+	}
+	if len(t.periods) == jt.Dimensions {
+		res.periods = t.periods
+	}
+	if jt.Root != nil {
+		var err error
+		if res.root, err = fromJSONNode(jt.Dimensions, jt.Root, 0, decodeItem); err != nil {
+			return err
+		}
+		var vs []T
+		walk(res.root, PreOrder, func(n *kdNode[T], _ int) bool {
+			vs = append(vs, n.value)
+			if n.deleted {
+				res.tombstones++
+			} else {
+				res.size++
+			}
+			return true
+		})
+		if err := checkDimensions(jt.Dimensions, vs); err != nil {
+			return err
+		}
+		if err := res.Validate(); err != nil {
+			return err
+		}
+		if !res.lazyDelete {
+			res.Compact()
+		}
+	} else {
+		vs := make([]T, 0, len(jt.Points))
+		for _, p := range jt.Points {
+			v, err := decodeItem(p)
+			if err != nil {
+				return err
+			}
+			vs = append(vs, v)
+		}
+		if err := checkDimensions(jt.Dimensions, vs); err != nil {
+			return err
+		}
+		res = res.withValues(vs)
+	}
+	if res.periods != nil {
+		for _, v := range res.Values() {
+			if err := checkPeriods(res.periods, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	*t = *res
+	return nil
+}
+
+func toJSONNode[T Comparable[T]](d int, r *kdNode[T], cd int, encodeItem func(T) ([]byte, error)) (*jsonNode, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	b, err := encodeItem(r.value)
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{
+		Value:     b,
+		Dimension: cd,
+		Deleted:   r.deleted,
+	}
+	ncd := (cd + 1) % d
+	if n.Left, err = toJSONNode(d, r.left, ncd, encodeItem); err != nil {
+		return nil, err
+	}
+	if n.Right, err = toJSONNode(d, r.right, ncd, encodeItem); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func fromJSONNode[T Comparable[T]](d int, n *jsonNode, cd int, decodeItem func([]byte) (T, error)) (*kdNode[T], error) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Dimension != cd {
+		return nil, fmt.Errorf("%w: node splits on dimension %d instead of %d", ErrInvalidTree, n.Dimension, cd)
+	}
+
+	v, err := decodeItem(n.Value)
+	if err != nil {
+		return nil, err
+	}
+	r := NewKDNode(v)
+	r.deleted = n.Deleted
+	ncd := (cd + 1) % d
+	if r.left, err = fromJSONNode(d, n.Left, ncd, decodeItem); err != nil {
+		return nil, err
+	}
+	if r.right, err = fromJSONNode(d, n.Right, ncd, decodeItem); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
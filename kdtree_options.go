@@ -0,0 +1,52 @@
+package kdtree
+
+import "fmt"
+
+// Option configures a tree created by New.
+type Option[T Comparable[T]] func(*KDTree[T]) error
+
+// New returns an empty tree with dims dimensions, configured by opts.
+func New[T Comparable[T]](dims int, opts ...Option[T]) (*KDTree[T], error) {
+	if dims <= 0 {
+		return nil, fmt.Errorf("%w: a tree needs at least one dimension, got %d", ErrInvalidDimension, dims)
+	}
+	t := &KDTree[T]{
+		dimensions: dims,
+		isSetup:    true,
+	}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// WithValues fills the tree with a balanced tree of vs. Values that occur more than once are stored once.
+func WithValues[T Comparable[T]](vs []T) Option[T] {
+	return func(t *KDTree[T]) error {
+		for _, v := range vs {
+			if err := checkPeriods(t.periods, v); err != nil {
+				return err
+			}
+		}
+		t.root = newSubtree(t.dimensions, vs, 0)
+		t.size = countNodes(t.root)
+		t.tombstones = 0
+		return nil
+	}
+}
+
+// WithLazyDelete makes the tree delete values lazily, as described by SetLazyDelete.
+func WithLazyDelete[T Comparable[T]](compactRatio float64) Option[T] {
+	return func(t *KDTree[T]) error {
+		return t.SetLazyDelete(true, compactRatio)
+	}
+}
+
+// WithPeriods makes the tree periodic, as described by SetPeriodic.
+func WithPeriods[T Comparable[T]](periods []float64) Option[T] {
+	return func(t *KDTree[T]) error {
+		return t.SetPeriodic(periods)
+	}
+}
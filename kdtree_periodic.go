@@ -0,0 +1,186 @@
+package kdtree
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	internal "github.com/rishitc/go-kd-tree/internal/utils"
+)
+
+// Translator is implemented by values that can be shifted along a dimension and report their coordinates.
+// Periodic trees need it to check that values lie within their periods and to search the images of a
+// query in the neighbouring periods.
+type Translator[T any] interface {
+	Translate(dim int, delta float64) T
+	Coord(dim int) float64
+}
+
+// SetPeriodic makes the tree periodic (toroidal) in every dimension with a non-zero period, so that
+// NearestNeighbor, KNN, RadiusSearch and RangeSearch wrap around the edges of the domain. Values must lie
+// within [0, period) in the periodic dimensions and T must implement Translator. It returns an error
+// wrapping ErrOutsidePeriod, leaving the tree unchanged, when a value of the tree does not. Passing nil
+// makes the tree non-periodic again.
+func (t *KDTree[T]) SetPeriodic(periods []float64) error {
+	if !t.isSetup {
+		return ErrTreeNotSetup
+	}
+	if periods == nil {
+		t.periods = nil
+		return nil
+	}
+	if len(periods) != t.dimensions {
+		return fmt.Errorf("%w: %d periods for a tree with %d dimensions", ErrDimensionMismatch, len(periods), t.dimensions)
+	}
+	for dim, period := range periods {
+		if period < 0 {
+			return fmt.Errorf("period of dimension %d is negative: %v", dim, period)
+		}
+	}
+	if _, ok := any(t.zeroVal).(Translator[T]); !ok {
+		return fmt.Errorf("%v does not implement Translator, which periodic trees need", reflect.TypeFor[T]())
+	}
+	for _, v := range t.Values() {
+		if err := checkPeriods(periods, v); err != nil {
+			return err
+		}
+	}
+	t.periods = slices.Clone(periods)
+	return nil
+}
+
+// checkPeriods returns an error wrapping ErrOutsidePeriod if v does not lie within [0, period) in every
+// periodic dimension.
+func checkPeriods[T any](periods []float64, v T) error {
+	for dim, period := range periods {
+		if period == 0 {
+			continue
+		}
+		if c := any(v).(Translator[T]).Coord(dim); !(c >= 0 && c < period) {
+			return fmt.Errorf("%w: %v has coordinate %v in dimension %d, whose period is %v",
+				ErrOutsidePeriod, v, c, dim, period)
+		}
+	}
+	return nil
+}
+
+// imageOffsets returns the offset of every image of a value in a periodic tree: the value itself and its
+// images shifted by one period in either direction in each periodic dimension.
+func (t *KDTree[T]) imageOffsets() [][]float64 {
+	offsets := [][]float64{nil}
+	for dim, period := range t.periods {
+		if period == 0 {
+			continue
+		}
+		for _, offset := range offsets {
+			plus, minus := make([]float64, t.dimensions), make([]float64, t.dimensions)
+			copy(plus, offset)
+			copy(minus, offset)
+			plus[dim], minus[dim] = period, -period
+			offsets = append(offsets, plus, minus)
+		}
+	}
+	return offsets
+}
+
+// imageBounds returns a lower bound of the distance between the image of value at each of offsets and any
+// value of the tree. As values lie within [0, period), an image shifted by a period is at least as far
+// from them as from the edge of the period it was shifted across.
+func (t *KDTree[T]) imageBounds(value T, offsets [][]float64) []int {
+	bounds := make([]int, len(offsets))
+	if t.periods == nil {
+		return bounds
+	}
+	above, below := make([]int, t.dimensions), make([]int, t.dimensions)
+	for dim, period := range t.periods {
+		if period == 0 {
+			continue
+		}
+		c := any(value).(Translator[T]).Coord(dim)
+		if !(c >= 0 && c < period) {
+			// The edges only bound the distance to images of queries within the period.
+			continue
+		}
+		shift := make([]float64, t.dimensions)
+		shift[dim] = period
+		edge := make([]float64, t.dimensions)
+		edge[dim] = period - c
+		above[dim] = internal.Abs(translate(value, shift).DistDim(translate(value, edge), dim))
+		shift[dim], edge[dim] = -period, -c
+		below[dim] = internal.Abs(translate(value, shift).DistDim(translate(value, edge), dim))
+	}
+	for i, offset := range offsets {
+		for dim, delta := range offset {
+			gap := 0
+			if delta > 0 {
+				gap = above[dim]
+			} else if delta < 0 {
+				gap = below[dim]
+			}
+			if gap > bounds[i] {
+				bounds[i] = gap
+			}
+		}
+	}
+	return bounds
+}
+
+func translate[T any](v T, offset []float64) T {
+	for dim, delta := range offset {
+		if delta != 0 {
+			v = any(v).(Translator[T]).Translate(dim, delta)
+		}
+	}
+	return v
+}
+
+// dedupe sorts vs by their super key and drops the values found through more than one image.
+func dedupe[T Comparable[T]](vs []T) []T {
+	slices.SortFunc(vs, func(a, b T) int { return a.Order(b, 0) })
+	return slices.CompactFunc(vs, func(a, b T) bool { return a.Order(b, 0) == 0 })
+}
+
+// periodicKNN finds the k nearest neighbours of every image of value and keeps the k values closest to
+// any of the images. Images farther away than the k-th value found so far are skipped. Like KNN, the
+// result is ordered from the farthest to the nearest value.
+func (t *KDTree[T]) periodicKNN(value T, k int, tr *queryTrace[T]) []T {
+	var best []Item[T]
+	offsets := t.imageOffsets()
+	bounds := t.imageBounds(value, offsets)
+	for i, offset := range offsets {
+		if len(best) == k && bounds[i] > best[k-1].Priority {
+			continue
+		}
+		image := translate(value, offset)
+		pq := NewBoundedPriorityQueue[T](k)
+		knn(k, t.dimensions, &image, &pq, 0, t.root, 0, tr)
+		best = nearestItems(append(best, pq.data...), k)
+	}
+
+	res := make([]T, len(best))
+	for i, item := range best {
+		res[len(best)-1-i] = *item.Data
+	}
+	return res
+}
+
+// nearestItems returns the k items of distinct values with the lowest priorities, ordered from the
+// nearest to the farthest.
+func nearestItems[T Comparable[T]](items []Item[T], k int) []Item[T] {
+	slices.SortFunc(items, func(a, b Item[T]) int {
+		if a.Priority != b.Priority {
+			return a.Priority - b.Priority
+		}
+		return (*a.Data).Order(*b.Data, 0)
+	})
+	res := items[:0]
+	for _, item := range items {
+		if len(res) == k {
+			break
+		}
+		if !slices.ContainsFunc(res, func(it Item[T]) bool { return (*it.Data).Order(*item.Data, 0) == 0 }) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
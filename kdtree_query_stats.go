@@ -0,0 +1,56 @@
+package kdtree
+
+// QueryStats records the work done by a single query, to help understand why a query is slow.
+type QueryStats struct {
+	// NodesVisited is the number of nodes of the tree the query looked at.
+	NodesVisited int
+	// DistanceEvaluations is the number of values the full distance to the query was computed for.
+	DistanceEvaluations int
+	// BranchesPruned is the number of non-empty subtrees skipped because they could not hold a result.
+	BranchesPruned int
+	// MaxDepth is the depth of the deepest node visited, with the root at depth 0.
+	MaxDepth int
+}
+
+// queryTrace follows a query through the tree. It records the work done in stats and, when visited is
+// set, the nodes the query looked at. A nil *queryTrace records nothing.
+type queryTrace[T Comparable[T]] struct {
+	stats   *QueryStats
+	visited map[*kdNode[T]]bool
+}
+
+func newQueryTrace[T Comparable[T]](stats *QueryStats) *queryTrace[T] {
+	if stats == nil {
+		return nil
+	}
+	return &queryTrace[T]{
+		stats: stats,
+	}
+}
+
+func (tr *queryTrace[T]) visit(n *kdNode[T], depth int) {
+	if tr == nil {
+		return
+	}
+	tr.stats.NodesVisited++
+	if depth > tr.stats.MaxDepth {
+		tr.stats.MaxDepth = depth
+	}
+	if tr.visited != nil {
+		tr.visited[n] = true
+	}
+}
+
+func (tr *queryTrace[T]) evaluate() {
+	if tr == nil {
+		return
+	}
+	tr.stats.DistanceEvaluations++
+}
+
+func (tr *queryTrace[T]) prune() {
+	if tr == nil {
+		return
+	}
+	tr.stats.BranchesPruned++
+}
@@ -0,0 +1,74 @@
+package kdtree
+
+import "math"
+
+// TreeStats describes the shape of a KDTree.
+type TreeStats[T Comparable[T]] struct {
+	// Nodes is the number of nodes in the tree, including the nodes marked as deleted.
+	Nodes        int
+	DeletedNodes int
+	// Height is the number of levels in the tree.
+	Height       int
+	MinLeafDepth int
+	AvgLeafDepth float64
+	MaxLeafDepth int
+	// LevelOccupancy holds the number of nodes at each depth, starting with the root.
+	LevelOccupancy []int
+	// Imbalance is the ratio of Height to the height of a perfectly balanced tree with the same number of
+	// nodes. A value of 1 means that the tree is balanced.
+	Imbalance float64
+	// MinBounds and MaxBounds hold the minimum and maximum value in each dimension.
+	MinBounds []T
+	MaxBounds []T
+}
+
+// Stats walks the tree and reports its shape, which can be used to decide when to call Balance.
+func (t *KDTree[T]) Stats() TreeStats[T] {
+	var s TreeStats[T]
+	if t.root == nil {
+		return s
+	}
+
+	leaves, leafDepthSum := 0, 0
+	var q Queue[*kdNode[T]] = NewLLQueue[*kdNode[T]]()
+	q.Push(t.root)
+	for depth := 0; !q.Empty(); depth++ {
+		size := q.Size()
+		s.LevelOccupancy = append(s.LevelOccupancy, size)
+		for i := 0; i < size; i++ {
+			n, _ := q.Pop()
+			s.Nodes++
+			if n.deleted {
+				s.DeletedNodes++
+			}
+			if n.left == nil && n.right == nil {
+				if leaves == 0 {
+					s.MinLeafDepth = depth
+				}
+				s.MaxLeafDepth = depth
+				leaves++
+				leafDepthSum += depth
+			}
+			if n.left != nil {
+				q.Push(n.left)
+			}
+			if n.right != nil {
+				q.Push(n.right)
+			}
+		}
+	}
+	s.Height = len(s.LevelOccupancy)
+	s.AvgLeafDepth = float64(leafDepthSum) / float64(leaves)
+	s.Imbalance = float64(s.Height) / math.Ceil(math.Log2(float64(s.Nodes+1)))
+
+	for dim := 0; dim < t.dimensions; dim++ {
+		minValue, err := t.FindMin(dim)
+		if err != nil {
+			break
+		}
+		maxValue, _ := t.FindMax(dim)
+		s.MinBounds = append(s.MinBounds, minValue)
+		s.MaxBounds = append(s.MaxBounds, maxValue)
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package kdtree
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	svgDefaultSize = 500
+	svgPointRadius = 3
+)
+
+var svgSplitColors = [2]string{"#d62728", "#1f77b4"}
+
+// SVGOptions configures how SVG renders a 2-dimensional tree.
+type SVGOptions[T Comparable[T]] struct {
+	// Coordinate returns the position of value in the dimension dim, which is either 0 (x) or 1 (y).
+	Coordinate func(value T, dim int) float64
+	// Width and Height of the image in pixels, both default to 500.
+	Width, Height int
+	// Bounds is the area to draw as {minX, minY, maxX, maxY}. When nil, it is the bounding box of the
+	// values in the tree with a small margin.
+	Bounds *[4]float64
+	// Query, when set, is drawn along with its K nearest neighbors in the tree.
+	Query *T
+	K     int
+	// Range, when set, highlights the cells visited by RangeSearch with it and the values it finds.
+	Range RangeFunc[T]
+}
+
+type svgCell [4]float64
+
+type svgCanvas struct {
+	b             strings.Builder
+	bounds        svgCell
+	width, height float64
+}
+
+func (c *svgCanvas) x(v float64) float64 {
+	return (v - c.bounds[0]) / (c.bounds[2] - c.bounds[0]) * c.width
+}
+
+func (c *svgCanvas) y(v float64) float64 {
+	// SVG's y axis points down, so flip it to draw the tree the way it is usually plotted.
+	return c.height - (v-c.bounds[1])/(c.bounds[3]-c.bounds[1])*c.height
+}
+
+func (c *svgCanvas) rect(cell svgCell, attributes string) {
+	fmt.Fprintf(&c.b, "  <rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" %s/>\n",
+		c.x(cell[0]), c.y(cell[3]), c.x(cell[2])-c.x(cell[0]), c.y(cell[1])-c.y(cell[3]), attributes)
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2 float64, attributes string) {
+	fmt.Fprintf(&c.b, "  <line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" %s/>\n",
+		c.x(x1), c.y(y1), c.x(x2), c.y(y2), attributes)
+}
+
+func (c *svgCanvas) circle(x, y float64, r int, attributes string) {
+	fmt.Fprintf(&c.b, "  <circle cx=\"%.2f\" cy=\"%.2f\" r=\"%d\" %s/>\n", c.x(x), c.y(y), r, attributes)
+}
+
+// SVG renders the spatial subdivision of a 2-dimensional tree: the bounding box, the splitting line of
+// every node, coloured by the dimension it splits on, and the values in the tree.
+func (t *KDTree[T]) SVG(opts SVGOptions[T]) (string, error) {
+	if t.dimensions != 2 {
+		return "", fmt.Errorf("%w: SVG needs a 2-dimensional tree, got %d dimensions", ErrInvalidDimension, t.dimensions)
+	}
+	if opts.Coordinate == nil {
+		return "", fmt.Errorf("SVG needs a Coordinate function to position the values")
+	}
+
+	c := svgCanvas{
+		width:  svgDefaultSize,
+		height: svgDefaultSize,
+	}
+	if opts.Width > 0 {
+		c.width = float64(opts.Width)
+	}
+	if opts.Height > 0 {
+		c.height = float64(opts.Height)
+	}
+	if opts.Bounds != nil {
+		c.bounds = *opts.Bounds
+	} else {
+		c.bounds = t.svgBounds(opts.Coordinate)
+	}
+
+	fmt.Fprintf(&c.b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\">\n", c.width, c.height)
+	c.rect(c.bounds, `fill="white" stroke="black"`)
+
+	var found []T
+	if opts.Range != nil {
+		svgRangeCells(&c, opts.Coordinate, opts.Range, t.root, 0, c.bounds, &found)
+	}
+	svgSplits(&c, opts.Coordinate, t.root, 0, c.bounds)
+
+	for _, v := range t.Values() {
+		c.circle(opts.Coordinate(v, 0), opts.Coordinate(v, 1), svgPointRadius, `fill="black"`)
+	}
+	for _, v := range found {
+		c.circle(opts.Coordinate(v, 0), opts.Coordinate(v, 1), svgPointRadius, `fill="#2ca02c"`)
+	}
+
+	if opts.Query != nil {
+		qx, qy := opts.Coordinate(*opts.Query, 0), opts.Coordinate(*opts.Query, 1)
+		for _, v := range t.KNN(*opts.Query, opts.K) {
+			x, y := opts.Coordinate(v, 0), opts.Coordinate(v, 1)
+			c.line(qx, qy, x, y, `stroke="#ff7f0e" stroke-dasharray="4"`)
+			c.circle(x, y, svgPointRadius, `fill="#2ca02c"`)
+		}
+		c.circle(qx, qy, svgPointRadius+1, `fill="#ff7f0e"`)
+	}
+
+	c.b.WriteString("</svg>\n")
+	return c.b.String(), nil
+}
+
+func (t *KDTree[T]) svgBounds(coordinate func(T, int) float64) svgCell {
+	var bounds svgCell
+	for dim := 0; dim < 2; dim++ {
+		lo, hi := 0.0, 1.0
+		if minValue, err := t.FindMin(dim); err == nil {
+			maxValue, _ := t.FindMax(dim)
+			lo, hi = coordinate(minValue, dim), coordinate(maxValue, dim)
+		}
+		margin := (hi - lo) * 0.05
+		if margin == 0 {
+			margin = 1
+		}
+		bounds[dim], bounds[dim+2] = lo-margin, hi+margin
+	}
+	return bounds
+}
+
+// splitCell splits cell along the line through split in the dimension cd.
+func splitCell(cell svgCell, cd int, split float64) (lower, upper svgCell) {
+	lower, upper = cell, cell
+	lower[cd+2] = split
+	upper[cd] = split
+	return lower, upper
+}
+
+func svgSplits[T Comparable[T]](c *svgCanvas, coordinate func(T, int) float64, r *kdNode[T], cd int, cell svgCell) {
+	if r == nil {
+		return
+	}
+
+	split := coordinate(r.value, cd)
+	attributes := fmt.Sprintf("stroke=\"%s\"", svgSplitColors[cd])
+	if r.deleted {
+		attributes += ` stroke-dasharray="2"`
+	}
+	if cd == 0 {
+		c.line(split, cell[1], split, cell[3], attributes)
+	} else {
+		c.line(cell[0], split, cell[2], split, attributes)
+	}
+
+	lower, upper := splitCell(cell, cd, split)
+	svgSplits(c, coordinate, r.left, 1-cd, lower)
+	svgSplits(c, coordinate, r.right, 1-cd, upper)
+}
+
+func svgRangeCells[T Comparable[T]](c *svgCanvas, coordinate func(T, int) float64, getRelativePosition RangeFunc[T], r *kdNode[T], cd int, cell svgCell, found *[]T) {
+	if r == nil {
+		return
+	}
+
+	c.rect(cell, `fill="#ffe08a" fill-opacity="0.5" stroke="none"`)
+	if !r.deleted && getRelativePosition(r.value, -1) == InRange {
+		*found = append(*found, r.value)
+	}
+
+	lower, upper := splitCell(cell, cd, coordinate(r.value, cd))
+	switch relInCD := getRelativePosition(r.value, cd); relInCD {
+	case BeforeRange:
+		svgRangeCells(c, coordinate, getRelativePosition, r.right, 1-cd, upper, found)
+	case AfterRange:
+		svgRangeCells(c, coordinate, getRelativePosition, r.left, 1-cd, lower, found)
+	case InRange:
+		svgRangeCells(c, coordinate, getRelativePosition, r.left, 1-cd, lower, found)
+		svgRangeCells(c, coordinate, getRelativePosition, r.right, 1-cd, upper, found)
+	default:
+		panic(fmt.Sprintf("Invalid value returned: %v", relInCD))
+	}
+}
@@ -12,7 +12,14 @@ const (
 	AfterRange
 )
 
-var ErrTreeNotSetup = fmt.Errorf("tree is not setup, make sure you create the tree using NewTree")
+var ErrTreeNotSetup = fmt.Errorf("tree is not setup, make sure you create the tree using New")
+var ErrDimensionMismatch = fmt.Errorf("trees do not have the same number of dimensions")
+var ErrInvalidDimension = fmt.Errorf("dimension is out of range for the tree")
+var ErrInvalidTree = fmt.Errorf("tree is invalid")
+var ErrInvalidEncoding = fmt.Errorf("encoded tree is invalid")
+var ErrEmptyTree = fmt.Errorf("tree is empty")
+var ErrInvalidTraversalOrder = fmt.Errorf("traversal order is invalid")
+var ErrOutsidePeriod = fmt.Errorf("value lies outside of the period of the tree")
 
 type KDTree[T Comparable[T]] struct {
 	dimensions int
@@ -20,10 +27,17 @@ type KDTree[T Comparable[T]] struct {
 	isSetup    bool
 	zeroVal    T
 	size       int
+
+	lazyDelete   bool
+	compactRatio float64
+	tombstones   int
+
+	periods []float64
 }
 
 type kdNode[T Comparable[T]] struct {
-	value T
-	left  *kdNode[T]
-	right *kdNode[T]
+	value   T
+	left    *kdNode[T]
+	right   *kdNode[T]
+	deleted bool
 }
@@ -0,0 +1,143 @@
+package kdtree
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ancestor[T Comparable[T]] struct {
+	node *kdNode[T]
+	dim  int
+	dir  direction
+}
+
+// Validate walks the tree and checks that every node lies on the correct side of the splitting
+// hyperplanes of all its ancestors, that the bookkeeping of the tree matches its nodes and that no value
+// is stored more than once. The returned error wraps ErrInvalidTree and names the path to the offending
+// node.
+func (t *KDTree[T]) Validate() error {
+	if t.root != nil && t.dimensions <= 0 {
+		return fmt.Errorf("%w: tree with %d dimensions holds values", ErrInvalidTree, t.dimensions)
+	}
+
+	var nodes []*kdNode[T]
+	if err := validateNode(t.dimensions, t.root, 0, nil, &nodes); err != nil {
+		return err
+	}
+
+	live, deleted := 0, 0
+	for _, n := range nodes {
+		if n.deleted {
+			deleted++
+		} else {
+			live++
+		}
+	}
+	if live != t.size {
+		return fmt.Errorf("%w: tree size is %d but it holds %d values", ErrInvalidTree, t.size, live)
+	}
+	if deleted != t.tombstones {
+		return fmt.Errorf("%w: tree counts %d deleted nodes but it holds %d", ErrInvalidTree, t.tombstones, deleted)
+	}
+
+	slices.SortFunc(nodes, func(a, b *kdNode[T]) int {
+		return a.value.Order(b.value, 0)
+	})
+	for i := 1; i < len(nodes); i++ {
+		if v := nodes[i].value; v.Order(nodes[i-1].value, 0) == 0 {
+			var paths []string
+			findPaths(t.root, v, nil, &paths)
+			return fmt.Errorf("%w: value %s is stored at %s", ErrInvalidTree, v, strings.Join(paths, " and "))
+		}
+	}
+	return nil
+}
+
+func validateNode[T Comparable[T]](d int, r *kdNode[T], cd int, ancestors []ancestor[T], nodes *[]*kdNode[T]) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, a := range ancestors {
+		rel := r.value.Order(a.node.value, a.dim)
+		if a.dir == left && rel >= 0 {
+			return fmt.Errorf("%w: value %s at %s is not before %s in dimension %d",
+				ErrInvalidTree, r.value, formatPath(ancestors), a.node.value, a.dim)
+		}
+		if a.dir == right && rel < 0 {
+			return fmt.Errorf("%w: value %s at %s is before %s in dimension %d",
+				ErrInvalidTree, r.value, formatPath(ancestors), a.node.value, a.dim)
+		}
+	}
+	*nodes = append(*nodes, r)
+
+	ncd := (cd + 1) % d
+	ancestors = append(ancestors, ancestor[T]{node: r, dim: cd, dir: left})
+	if err := validateNode(d, r.left, ncd, ancestors, nodes); err != nil {
+		return err
+	}
+	ancestors[len(ancestors)-1].dir = right
+	return validateNode(d, r.right, ncd, ancestors, nodes)
+}
+
+func findPaths[T Comparable[T]](r *kdNode[T], value T, ancestors []ancestor[T], paths *[]string) {
+	if r == nil {
+		return
+	}
+
+	if r.value.Order(value, 0) == 0 {
+		*paths = append(*paths, formatPath(ancestors))
+	}
+	ancestors = append(ancestors, ancestor[T]{node: r, dir: left})
+	findPaths(r.left, value, ancestors, paths)
+	ancestors[len(ancestors)-1].dir = right
+	findPaths(r.right, value, ancestors, paths)
+}
+
+// formatPath describes the path taken from the root through ancestors, e.g. "root.left.right".
+func formatPath[T Comparable[T]](ancestors []ancestor[T]) string {
+	b := strings.Builder{}
+	b.WriteString("root")
+	for _, a := range ancestors {
+		if a.dir == left {
+			b.WriteString(".left")
+		} else {
+			b.WriteString(".right")
+		}
+	}
+	return b.String()
+}
+
+// checkDimensions reports whether every value of vs can be used in a tree with d dimensions. Values
+// implementing Dimensioned must have d dimensions. Other values are ordered and measured against the
+// first value in each of the d dimensions, and the panic of a value with fewer coordinates, typically an
+// index out of range, is turned into an error wrapping ErrDimensionMismatch.
+func checkDimensions[T Comparable[T]](d int, vs []T) (err error) {
+	for i, v := range vs {
+		if dv, ok := any(v).(Dimensioned); ok && dv.Dimensions() != d {
+			return fmt.Errorf("%w: value %d has %d dimensions instead of %d", ErrDimensionMismatch, i, dv.Dimensions(), d)
+		}
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: values can not be used in a tree with %d dimensions: %v", ErrDimensionMismatch, d, r)
+		}
+	}()
+	first := vs[0]
+	for _, v := range vs {
+		v.Dist(first)
+		first.Dist(v)
+		for dim := 0; dim < d; dim++ {
+			v.Order(first, dim)
+			first.Order(v, dim)
+			v.DistDim(first, dim)
+			first.DistDim(v, dim)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package kdtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// DecodeOptions controls how NewKDTreeFromBytesWithOptions treats encoded trees, which may come from
+// untrusted sources. A limit of 0 disables the corresponding check.
+type DecodeOptions struct {
+	// Dimensions rejects trees with any other number of dimensions. Set it when the values can only be
+	// ordered in a fixed number of dimensions, such as fixed size arrays.
+	Dimensions int
+
+	MaxBytes      int
+	MaxItems      int
+	MaxItemBytes  int
+	MaxDimensions int
+}
+
+// DefaultDecodeOptions are the options used by NewKDTreeFromBytesWith.
+var DefaultDecodeOptions = DecodeOptions{
+	MaxBytes:      1 << 30,
+	MaxItems:      1 << 24,
+	MaxItemBytes:  1 << 20,
+	MaxDimensions: 1 << 10,
+}
+
+// Slots of the fields of the KDTree and Item tables in format.fbs.
+const (
+	kdTreeVersionNumberSlot = iota
+	kdTreeDimensionsSlot
+	kdTreeInorderIndicesSlot
+	kdTreeItemsSlot
+	kdTreeItemSizeSlot
+	kdTreePackedItemsSlot
+	kdTreeDeltaEncodedSlot
+)
+
+const itemDataSlot = 0
+
+// verifyEncoding checks that every offset, table and vector of an encoded tree lies within encodedBytes
+// and that the tree respects the limits in opts, so that the generated accessors can not read out of
+// bounds.
+func verifyEncoding(encodedBytes []byte, opts DecodeOptions) error {
+	if opts.MaxBytes > 0 && len(encodedBytes) > opts.MaxBytes {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d", ErrInvalidEncoding, len(encodedBytes), opts.MaxBytes)
+	}
+	v := flatVerifier{buf: encodedBytes}
+	rootPos, err := v.uoffset(0)
+	if err != nil {
+		return err
+	}
+	tree, err := v.table(rootPos)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tree.uint32(kdTreeVersionNumberSlot); err != nil {
+		return err
+	}
+	dimensions, err := tree.uint32(kdTreeDimensionsSlot)
+	if err != nil {
+		return err
+	}
+	if opts.Dimensions > 0 && int64(dimensions) != int64(opts.Dimensions) {
+		return fmt.Errorf("%w: tree has %d dimensions instead of %d", ErrInvalidEncoding, dimensions, opts.Dimensions)
+	}
+	if opts.MaxDimensions > 0 && int64(dimensions) > int64(opts.MaxDimensions) {
+		return fmt.Errorf("%w: %d dimensions exceed the limit of %d", ErrInvalidEncoding, dimensions, opts.MaxDimensions)
+	}
+	if int64(dimensions) > math.MaxInt32 {
+		return fmt.Errorf("%w: %d dimensions do not fit in an int", ErrInvalidEncoding, dimensions)
+	}
+
+	itemSize, err := tree.uint32(kdTreeItemSizeSlot)
+	if err != nil {
+		return err
+	}
+	itemsStart, itemsLength, err := tree.vector(kdTreeItemsSlot, 4)
+	if err != nil {
+		return err
+	}
+	packedStart, packedLength, err := tree.vector(kdTreePackedItemsSlot, 1)
+	if err != nil {
+		return err
+	}
+	deltaEncoded, err := tree.bool(kdTreeDeltaEncodedSlot)
+	if err != nil {
+		return err
+	}
+	itemCount := itemsLength
+	if deltaEncoded {
+		if itemSize > 0 || itemsLength > 0 {
+			return fmt.Errorf("%w: delta encoded tree also has unpacked or fixed size items", ErrInvalidEncoding)
+		}
+		deltaItems, err := unpackDeltaItems(encodedBytes[packedStart : packedStart+packedLength])
+		if err != nil {
+			return err
+		}
+		for i, item := range deltaItems {
+			if opts.MaxItemBytes > 0 && len(item) > opts.MaxItemBytes {
+				return fmt.Errorf("%w: item %d has %d bytes, more than the limit of %d", ErrInvalidEncoding, i, len(item), opts.MaxItemBytes)
+			}
+		}
+		itemCount = len(deltaItems)
+	} else if itemSize > 0 {
+		if opts.MaxItemBytes > 0 && int64(itemSize) > int64(opts.MaxItemBytes) {
+			return fmt.Errorf("%w: items of %d bytes exceed the limit of %d", ErrInvalidEncoding, itemSize, opts.MaxItemBytes)
+		}
+		if int64(itemSize) > int64(len(encodedBytes)) {
+			return fmt.Errorf("%w: items of %d bytes are larger than the tree", ErrInvalidEncoding, itemSize)
+		}
+		itemCount = packedLength / int(itemSize)
+	}
+	if opts.MaxItems > 0 && itemCount > opts.MaxItems {
+		return fmt.Errorf("%w: %d items exceed the limit of %d", ErrInvalidEncoding, itemCount, opts.MaxItems)
+	}
+
+	if itemSize == 0 {
+		for i := 0; i < itemsLength; i++ {
+			itemPos, err := v.uoffset(itemsStart + 4*i)
+			if err != nil {
+				return err
+			}
+			item, err := v.table(itemPos)
+			if err != nil {
+				return err
+			}
+			_, dataLength, err := item.vector(itemDataSlot, 1)
+			if err != nil {
+				return err
+			}
+			if opts.MaxItemBytes > 0 && dataLength > opts.MaxItemBytes {
+				return fmt.Errorf("%w: item %d has %d bytes, more than the limit of %d", ErrInvalidEncoding, i, dataLength, opts.MaxItemBytes)
+			}
+		}
+	}
+
+	indicesStart, indicesLength, err := tree.vector(kdTreeInorderIndicesSlot, 8)
+	if err != nil {
+		return err
+	}
+	if indicesLength != itemCount {
+		return fmt.Errorf("%w: the number of the indices (%d) are not the same as the number of items (%d)",
+			ErrInvalidEncoding, indicesLength, itemCount)
+	}
+	seen := make([]bool, indicesLength)
+	for i := 0; i < indicesLength; i++ {
+		idx := int64(binary.LittleEndian.Uint64(encodedBytes[indicesStart+8*i:]))
+		if idx < 0 || idx >= int64(indicesLength) || seen[idx] {
+			return fmt.Errorf("%w: inorder indices are not a permutation of the items", ErrInvalidEncoding)
+		}
+		seen[idx] = true
+	}
+	return nil
+}
+
+// flatVerifier checks the structures of a FlatBuffers buffer before they are read.
+type flatVerifier struct {
+	buf []byte
+}
+
+type flatTable struct {
+	v          flatVerifier
+	pos        int
+	vtable     int
+	vtableSize int
+	size       int
+}
+
+func (v flatVerifier) check(pos, size int) error {
+	if pos < 0 || size < 0 || pos > len(v.buf)-size {
+		return fmt.Errorf("%w: %d bytes at offset %d are out of bounds", ErrInvalidEncoding, size, pos)
+	}
+	return nil
+}
+
+// uoffset returns the position referenced by the unsigned offset stored at pos.
+func (v flatVerifier) uoffset(pos int) (int, error) {
+	if err := v.check(pos, 4); err != nil {
+		return 0, err
+	}
+	offset := binary.LittleEndian.Uint32(v.buf[pos:])
+	if int64(offset) > int64(len(v.buf)-pos) {
+		return 0, fmt.Errorf("%w: offset %d at %d is out of bounds", ErrInvalidEncoding, offset, pos)
+	}
+	return pos + int(offset), nil
+}
+
+func (v flatVerifier) table(pos int) (flatTable, error) {
+	if err := v.check(pos, 4); err != nil {
+		return flatTable{}, err
+	}
+	vtable64 := int64(pos) - int64(int32(binary.LittleEndian.Uint32(v.buf[pos:])))
+	if vtable64 < 0 || vtable64 > int64(len(v.buf)-4) {
+		return flatTable{}, fmt.Errorf("%w: vtable at offset %d is out of bounds", ErrInvalidEncoding, vtable64)
+	}
+	vtable := int(vtable64)
+	vtableSize := int(binary.LittleEndian.Uint16(v.buf[vtable:]))
+	size := int(binary.LittleEndian.Uint16(v.buf[vtable+2:]))
+	if vtableSize < 4 || vtableSize%2 != 0 || size < 4 {
+		return flatTable{}, fmt.Errorf("%w: malformed vtable at offset %d", ErrInvalidEncoding, vtable)
+	}
+	if err := v.check(vtable, vtableSize); err != nil {
+		return flatTable{}, err
+	}
+	if err := v.check(pos, size); err != nil {
+		return flatTable{}, err
+	}
+	return flatTable{v: v, pos: pos, vtable: vtable, vtableSize: vtableSize, size: size}, nil
+}
+
+// field returns the position of the field in slot, which takes up size bytes, and false if the field is
+// not set.
+func (t flatTable) field(slot, size int) (int, bool, error) {
+	entry := 4 + 2*slot
+	if entry+2 > t.vtableSize {
+		return 0, false, nil
+	}
+	offset := int(binary.LittleEndian.Uint16(t.v.buf[t.vtable+entry:]))
+	if offset == 0 {
+		return 0, false, nil
+	}
+	if offset+size > t.size {
+		return 0, false, fmt.Errorf("%w: field %d lies outside of its table", ErrInvalidEncoding, slot)
+	}
+	return t.pos + offset, true, nil
+}
+
+func (t flatTable) bool(slot int) (bool, error) {
+	pos, ok, err := t.field(slot, 1)
+	if !ok || err != nil {
+		return false, err
+	}
+	return t.v.buf[pos] != 0, nil
+}
+
+func (t flatTable) uint32(slot int) (uint32, error) {
+	pos, ok, err := t.field(slot, 4)
+	if !ok || err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(t.v.buf[pos:]), nil
+}
+
+// vector returns the position of the first element and the length of the vector in slot, whose elements
+// take up elemSize bytes each.
+func (t flatTable) vector(slot, elemSize int) (int, int, error) {
+	pos, ok, err := t.field(slot, 4)
+	if !ok || err != nil {
+		return 0, 0, err
+	}
+	vec, err := t.v.uoffset(pos)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := t.v.check(vec, 4); err != nil {
+		return 0, 0, err
+	}
+	n := binary.LittleEndian.Uint32(t.v.buf[vec:])
+	if int64(n) > int64(len(t.v.buf)/elemSize) {
+		return 0, 0, fmt.Errorf("%w: vector of %d elements at offset %d is out of bounds", ErrInvalidEncoding, n, vec)
+	}
+	length := int(n)
+	if err := t.v.check(vec+4, length*elemSize); err != nil {
+		return 0, 0, err
+	}
+	return vec + 4, length, nil
+}
@@ -0,0 +1,96 @@
+package kdtree
+
+import "fmt"
+
+type TraversalOrder int
+
+const (
+	PreOrder TraversalOrder = iota
+	InOrder
+	PostOrder
+	LevelOrder
+)
+
+// NodeInfo describes a node of the tree visited by Walk.
+type NodeInfo[T Comparable[T]] struct {
+	Value          T
+	Depth          int
+	SplitDimension int
+	HasLeft        bool
+	HasRight       bool
+	// Deleted is set for nodes marked as deleted by a lazy Remove, which still take part in the structure
+	// of the tree.
+	Deleted bool
+}
+
+// Walk calls fn for every node of the tree in the given order, stopping as soon as fn returns false. It
+// returns ErrInvalidTraversalOrder for any other order than the ones declared above.
+func (t *KDTree[T]) Walk(order TraversalOrder, fn func(NodeInfo[T]) bool) error {
+	if order < PreOrder || order > LevelOrder {
+		return fmt.Errorf("%w: %d", ErrInvalidTraversalOrder, order)
+	}
+	walk(t.root, order, func(n *kdNode[T], depth int) bool {
+		return fn(NodeInfo[T]{
+			Value:          n.value,
+			Depth:          depth,
+			SplitDimension: depth % t.dimensions,
+			HasLeft:        n.left != nil,
+			HasRight:       n.right != nil,
+			Deleted:        n.deleted,
+		})
+	})
+	return nil
+}
+
+func walk[T Comparable[T]](r *kdNode[T], order TraversalOrder, fn func(*kdNode[T], int) bool) {
+	switch order {
+	case PreOrder, InOrder, PostOrder:
+		walkDepthFirst(r, order, 0, fn)
+	case LevelOrder:
+		walkLevelOrder(r, fn)
+	default:
+		panic(fmt.Sprintf("Invalid traversal order: %v", order))
+	}
+}
+
+func walkDepthFirst[T Comparable[T]](r *kdNode[T], order TraversalOrder, depth int, fn func(*kdNode[T], int) bool) bool {
+	if r == nil {
+		return true
+	}
+	if order == PreOrder && !fn(r, depth) {
+		return false
+	}
+	if !walkDepthFirst(r.left, order, depth+1, fn) {
+		return false
+	}
+	if order == InOrder && !fn(r, depth) {
+		return false
+	}
+	if !walkDepthFirst(r.right, order, depth+1, fn) {
+		return false
+	}
+	return order != PostOrder || fn(r, depth)
+}
+
+func walkLevelOrder[T Comparable[T]](r *kdNode[T], fn func(*kdNode[T], int) bool) {
+	if r == nil {
+		return
+	}
+	var q Queue[*kdNode[T]] = NewLLQueue[*kdNode[T]]()
+	q.Push(r)
+	for depth := 0; !q.Empty(); depth++ {
+		size := q.Size()
+		for i := 0; i < size; i++ {
+			n, _ := q.Pop()
+			if !fn(n, depth) {
+				return
+			}
+			if n.left != nil {
+				q.Push(n.left)
+			}
+			if n.right != nil {
+				q.Push(n.right)
+			}
+		}
+	}
+}
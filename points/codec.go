@@ -0,0 +1,132 @@
+package points
+
+import (
+	"fmt"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	internal "github.com/rishitc/go-kd-tree/internal/utils"
+)
+
+var (
+	_ kdtree.FixedSizeCodec[Int2D]   = Int2DCodec{}
+	_ kdtree.FixedSizeCodec[Int3D]   = Int3DCodec{}
+	_ kdtree.FixedSizeCodec[Float2D] = Float2DCodec{}
+	_ kdtree.FixedSizeCodec[Float3D] = Float3DCodec{}
+	_ kdtree.FixedSizeCodec[LatLon]  = LatLonCodec{}
+	_ kdtree.Codec[VecF64]           = VecF64Codec{}
+)
+
+// Int2DCodec encodes each coordinate of an Int2D as a little-endian 64-bit integer.
+type Int2DCodec struct{}
+
+func (Int2DCodec) Encode(v Int2D) ([]byte, error) {
+	return internal.EncodeFixedInts(v[:]), nil
+}
+
+func (Int2DCodec) Decode(b []byte) (Int2D, error) {
+	var v Int2D
+	err := internal.DecodeFixedInts(b, v[:])
+	return v, err
+}
+
+func (Int2DCodec) Name() string {
+	return "int2d/fixed64"
+}
+
+func (Int2DCodec) Size() int {
+	return len(Int2D{}) * internal.FixedCoordinateSize
+}
+
+// Int3DCodec encodes each coordinate of an Int3D as a little-endian 64-bit integer.
+type Int3DCodec struct{}
+
+func (Int3DCodec) Encode(v Int3D) ([]byte, error) {
+	return internal.EncodeFixedInts(v[:]), nil
+}
+
+func (Int3DCodec) Decode(b []byte) (Int3D, error) {
+	var v Int3D
+	err := internal.DecodeFixedInts(b, v[:])
+	return v, err
+}
+
+func (Int3DCodec) Name() string {
+	return "int3d/fixed64"
+}
+
+func (Int3DCodec) Size() int {
+	return len(Int3D{}) * internal.FixedCoordinateSize
+}
+
+// Float2DCodec encodes each coordinate of a Float2D as a little-endian IEEE 754 double.
+type Float2DCodec struct{}
+
+func (Float2DCodec) Encode(v Float2D) ([]byte, error) {
+	return internal.EncodeFloats(v[:]), nil
+}
+
+func (Float2DCodec) Decode(b []byte) (Float2D, error) {
+	var v Float2D
+	err := internal.DecodeFloats(b, v[:])
+	return v, err
+}
+
+func (Float2DCodec) Name() string {
+	return "float2d/float64"
+}
+
+func (Float2DCodec) Size() int {
+	return len(Float2D{}) * internal.FixedCoordinateSize
+}
+
+// Float3DCodec encodes each coordinate of a Float3D as a little-endian IEEE 754 double.
+type Float3DCodec struct{}
+
+func (Float3DCodec) Encode(v Float3D) ([]byte, error) {
+	return internal.EncodeFloats(v[:]), nil
+}
+
+func (Float3DCodec) Decode(b []byte) (Float3D, error) {
+	var v Float3D
+	err := internal.DecodeFloats(b, v[:])
+	return v, err
+}
+
+func (Float3DCodec) Name() string {
+	return "float3d/float64"
+}
+
+func (Float3DCodec) Size() int {
+	return len(Float3D{}) * internal.FixedCoordinateSize
+}
+
+// VecF64Codec encodes each coordinate of a VecF64 as a little-endian IEEE 754 double. When Dimensions is
+// set, vectors with any other number of coordinates are rejected.
+type VecF64Codec struct {
+	Dimensions int
+}
+
+func (c VecF64Codec) Encode(v VecF64) ([]byte, error) {
+	if c.Dimensions > 0 && len(v) != c.Dimensions {
+		return nil, fmt.Errorf("expected a vector with %d coordinates, got %d", c.Dimensions, len(v))
+	}
+	return internal.EncodeFloats(v), nil
+}
+
+func (c VecF64Codec) Decode(b []byte) (VecF64, error) {
+	if len(b)%internal.FixedCoordinateSize != 0 {
+		return nil, fmt.Errorf("%d bytes do not hold a whole number of coordinates", len(b))
+	}
+	v := make(VecF64, len(b)/internal.FixedCoordinateSize)
+	if err := internal.DecodeFloats(b, v); err != nil {
+		return nil, err
+	}
+	if c.Dimensions > 0 && len(v) != c.Dimensions {
+		return nil, fmt.Errorf("expected a vector with %d coordinates, got %d", c.Dimensions, len(v))
+	}
+	return v, nil
+}
+
+func (VecF64Codec) Name() string {
+	return "vecf64/float64"
+}
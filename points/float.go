@@ -0,0 +1,107 @@
+package points
+
+import (
+	"slices"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+)
+
+// Float2D is a point with two float64 coordinates.
+type Float2D [2]float64
+
+func (lhs Float2D) Order(rhs Float2D, dim int) int {
+	return order(lhs[:], rhs[:], dim)
+}
+
+func (lhs Float2D) Dist(rhs Float2D) int {
+	return kdtree.FloatDistKey(squaredDist(lhs[:], rhs[:]))
+}
+
+func (lhs Float2D) DistDim(rhs Float2D, dim int) int {
+	d := lhs[dim] - rhs[dim]
+	return kdtree.FloatDistKey(d * d)
+}
+
+// Translate shifts the point by delta along dimension dim.
+func (lhs Float2D) Translate(dim int, delta float64) Float2D {
+	lhs[dim] += delta
+	return lhs
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs Float2D) Coord(dim int) float64 {
+	return lhs[dim]
+}
+
+func (lhs Float2D) String() string {
+	return format("%g", lhs[:])
+}
+
+// Float3D is a point with three float64 coordinates.
+type Float3D [3]float64
+
+func (lhs Float3D) Order(rhs Float3D, dim int) int {
+	return order(lhs[:], rhs[:], dim)
+}
+
+func (lhs Float3D) Dist(rhs Float3D) int {
+	return kdtree.FloatDistKey(squaredDist(lhs[:], rhs[:]))
+}
+
+func (lhs Float3D) DistDim(rhs Float3D, dim int) int {
+	d := lhs[dim] - rhs[dim]
+	return kdtree.FloatDistKey(d * d)
+}
+
+// Translate shifts the point by delta along dimension dim.
+func (lhs Float3D) Translate(dim int, delta float64) Float3D {
+	lhs[dim] += delta
+	return lhs
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs Float3D) Coord(dim int) float64 {
+	return lhs[dim]
+}
+
+func (lhs Float3D) String() string {
+	return format("%g", lhs[:])
+}
+
+// VecF64 is a point with any number of float64 coordinates. All the points in a tree must have as many
+// coordinates as the tree has dimensions.
+type VecF64 []float64
+
+func (lhs VecF64) Order(rhs VecF64, dim int) int {
+	return order(lhs, rhs, dim)
+}
+
+func (lhs VecF64) Dist(rhs VecF64) int {
+	return kdtree.FloatDistKey(squaredDist(lhs, rhs))
+}
+
+func (lhs VecF64) DistDim(rhs VecF64, dim int) int {
+	d := lhs[dim] - rhs[dim]
+	return kdtree.FloatDistKey(d * d)
+}
+
+// Translate returns a copy of the point shifted by delta along dimension dim.
+func (lhs VecF64) Translate(dim int, delta float64) VecF64 {
+	res := slices.Clone(lhs)
+	res[dim] += delta
+	return res
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs VecF64) Coord(dim int) float64 {
+	return lhs[dim]
+}
+
+// Dimensions returns the number of coordinates of the point.
+func (lhs VecF64) Dimensions() int {
+	return len(lhs)
+}
+
+func (lhs VecF64) String() string {
+	return format("%g", lhs)
+}
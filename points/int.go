@@ -0,0 +1,65 @@
+package points
+
+import "math"
+
+// Int2D is a point with two integer coordinates.
+type Int2D [2]int
+
+func (lhs Int2D) Order(rhs Int2D, dim int) int {
+	return order(lhs[:], rhs[:], dim)
+}
+
+func (lhs Int2D) Dist(rhs Int2D) int {
+	return squaredDist(lhs[:], rhs[:])
+}
+
+func (lhs Int2D) DistDim(rhs Int2D, dim int) int {
+	d := lhs[dim] - rhs[dim]
+	return d * d
+}
+
+// Translate shifts the point by delta, rounded to the nearest integer, along dimension dim.
+func (lhs Int2D) Translate(dim int, delta float64) Int2D {
+	lhs[dim] += int(math.Round(delta))
+	return lhs
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs Int2D) Coord(dim int) float64 {
+	return float64(lhs[dim])
+}
+
+func (lhs Int2D) String() string {
+	return format("%d", lhs[:])
+}
+
+// Int3D is a point with three integer coordinates.
+type Int3D [3]int
+
+func (lhs Int3D) Order(rhs Int3D, dim int) int {
+	return order(lhs[:], rhs[:], dim)
+}
+
+func (lhs Int3D) Dist(rhs Int3D) int {
+	return squaredDist(lhs[:], rhs[:])
+}
+
+func (lhs Int3D) DistDim(rhs Int3D, dim int) int {
+	d := lhs[dim] - rhs[dim]
+	return d * d
+}
+
+// Translate shifts the point by delta, rounded to the nearest integer, along dimension dim.
+func (lhs Int3D) Translate(dim int, delta float64) Int3D {
+	lhs[dim] += int(math.Round(delta))
+	return lhs
+}
+
+// Coord returns the coordinate of the point in dimension dim.
+func (lhs Int3D) Coord(dim int) float64 {
+	return float64(lhs[dim])
+}
+
+func (lhs Int3D) String() string {
+	return format("%d", lhs[:])
+}
@@ -0,0 +1,94 @@
+package points
+
+import (
+	"fmt"
+	"math"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	internal "github.com/rishitc/go-kd-tree/internal/utils"
+)
+
+// EarthRadiusMetres is the mean radius of the earth used by LatLon.
+const EarthRadiusMetres = 6371008.8
+
+// LatLon is a geographic position in degrees. Trees of LatLon values have two dimensions, 0 for the
+// latitude and 1 for the longitude, and measure distances along great circles in metres, so that
+// NearestNeighbor, KNN and RadiusSearch work on the surface of the earth and across the antimeridian.
+// Longitudes must be in the range [-180, 180].
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// Metres converts a distance in metres into the unit of LatLon.Dist, e.g. for RadiusSearch.
+func Metres(m float64) int {
+	return kdtree.FloatDistKey(m)
+}
+
+func (lhs LatLon) Order(rhs LatLon, dim int) int {
+	return order([]float64{lhs.Lat, lhs.Lon}, []float64{rhs.Lat, rhs.Lon}, dim)
+}
+
+func (lhs LatLon) Dist(rhs LatLon) int {
+	return kdtree.FloatDistKey(lhs.DistanceMetres(rhs))
+}
+
+// DistDim returns a lower bound of the distance from lhs to any position on the other side of rhs in
+// dimension dim. For the longitude the other side wraps around the antimeridian, so the bound is the
+// distance to the nearer of the two meridians delimiting it.
+func (lhs LatLon) DistDim(rhs LatLon, dim int) int {
+	if dim == 0 {
+		return kdtree.FloatDistKey(EarthRadiusMetres * math.Abs(radians(lhs.Lat-rhs.Lat)))
+	}
+	lat := radians(lhs.Lat)
+	toSplit := meridianDist(lat, radians(lhs.Lon-rhs.Lon))
+	toAntimeridian := meridianDist(lat, radians(lhs.Lon-180))
+	return kdtree.FloatDistKey(EarthRadiusMetres * math.Min(toSplit, toAntimeridian))
+}
+
+// DistanceMetres returns the great-circle distance between lhs and rhs using the haversine formula.
+func (lhs LatLon) DistanceMetres(rhs LatLon) float64 {
+	lat1, lat2 := radians(lhs.Lat), radians(rhs.Lat)
+	sinLat := math.Sin((lat2 - lat1) / 2)
+	sinLon := math.Sin(radians(rhs.Lon-lhs.Lon) / 2)
+	h := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLon*sinLon
+	return 2 * EarthRadiusMetres * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func (lhs LatLon) String() string {
+	return fmt.Sprintf("(%g, %g)", lhs.Lat, lhs.Lon)
+}
+
+// meridianDist returns the angular distance from a position at latitude lat to the meridian that is
+// dLon radians away from it. Beyond a quarter turn the nearest point of the meridian is a pole.
+func meridianDist(lat, dLon float64) float64 {
+	if math.Cos(dLon) < 0 {
+		return math.Pi/2 - math.Abs(lat)
+	}
+	return math.Asin(math.Min(1, math.Cos(lat)*math.Abs(math.Sin(dLon))))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// LatLonCodec encodes the latitude and longitude of a LatLon as little-endian IEEE 754 doubles.
+type LatLonCodec struct{}
+
+func (LatLonCodec) Encode(v LatLon) ([]byte, error) {
+	return internal.EncodeFloats([]float64{v.Lat, v.Lon}), nil
+}
+
+func (LatLonCodec) Decode(b []byte) (LatLon, error) {
+	var c [2]float64
+	err := internal.DecodeFloats(b, c[:])
+	return LatLon{Lat: c[0], Lon: c[1]}, err
+}
+
+func (LatLonCodec) Name() string {
+	return "latlon/float64"
+}
+
+func (LatLonCodec) Size() int {
+	return 2 * internal.FixedCoordinateSize
+}
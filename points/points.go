@@ -0,0 +1,46 @@
+// Package points provides ready-made point types that implement kdtree.Comparable, along with codecs to
+// encode trees holding them.
+//
+// The integer points use the squared euclidean distance as their distance. The floating point points
+// use the squared euclidean distance mapped through kdtree.FloatDistKey.
+package points
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// order compares the super keys of l and r starting from dim, which is the "Optimized Super Key
+// Comparison" from the paper: dim is compared first and the remaining dimensions cyclically after it.
+func order[E cmp.Ordered](l, r []E, dim int) int {
+	for i := 0; i < len(l); i++ {
+		if c := cmp.Compare(l[dim], r[dim]); c != 0 {
+			return c
+		}
+		dim = (dim + 1) % len(l)
+	}
+	return 0
+}
+
+func squaredDist[E int | float64](l, r []E) E {
+	var sum E
+	for i := range l {
+		d := l[i] - r[i]
+		sum += d * d
+	}
+	return sum
+}
+
+func format[E any](verb string, v []E) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, c := range v {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, verb, c)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
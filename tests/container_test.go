@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	types "github.com/rishitc/go-kd-tree/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainer(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, []types.Tensor2D{{3, 2}, {5, 8}, {6, 1}, {9, 0}, {4, 4}})
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	opts := kdtree.ContainerOptions{
+		Metadata: map[string]string{"tile": "12/2048/1361", "source": "survey"},
+		Created:  created,
+	}
+
+	path := filepath.Join(t.TempDir(), "tree.kdt")
+	assert.NoError(t, tree.SaveFile(path, types.Tensor2DFixedCodec{}, opts))
+	loaded, header, err := kdtree.LoadFile(path, types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	assert.True(t, loaded.Equal(tree))
+	assert.Equal(t, kdtree.ContainerHeader{
+		Version:    2,
+		Codec:      "tensor2d/fixed64",
+		Dimensions: dimensions2DCount,
+		Count:      5,
+		Created:    created,
+		Metadata:   opts.Metadata,
+	}, header)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	_, _, err = kdtree.LoadFile(path, types.Tensor2DVarintCodec{})
+	assert.ErrorContains(t, err, `values were encoded with codec "tensor2d/fixed64", not "tensor2d/varint"`)
+	_, _, err = kdtree.LoadFile(filepath.Join(t.TempDir(), "missing.kdt"), types.Tensor2DFixedCodec{})
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	h, err := kdtree.ReadContainerHeader(b)
+	assert.NoError(t, err)
+	assert.Equal(t, header, h)
+
+	for n := 0; n < len(b); n++ {
+		_, _, err := kdtree.UnmarshalContainer(b[:n], types.Tensor2DFixedCodec{})
+		assert.True(t, errors.Is(err, kdtree.ErrInvalidContainer), "truncated to %d bytes: %v", n, err)
+	}
+	for i := range b {
+		corrupt := append([]byte(nil), b...)
+		corrupt[i] ^= 0x10
+		_, _, err := kdtree.UnmarshalContainer(corrupt, types.Tensor2DFixedCodec{})
+		assert.Error(t, err, "byte %d corrupted", i)
+	}
+	_, _, err = kdtree.UnmarshalContainer(append(b, 0), types.Tensor2DFixedCodec{})
+	assert.True(t, errors.Is(err, kdtree.ErrInvalidContainer))
+	corrupt := append([]byte(nil), b...)
+	corrupt[len(corrupt)-10] ^= 0x01
+	_, _, err = kdtree.UnmarshalContainer(corrupt, types.Tensor2DFixedCodec{})
+	assert.True(t, errors.Is(err, kdtree.ErrChecksumMismatch))
+}
+
+func TestContainerDimensionMismatch(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(dimensions3DCount, []types.Tensor3D{{3, 2, 1}, {5, 8, 0}, {6, 1, 7}})
+	path := filepath.Join(t.TempDir(), "tree.kdt")
+	assert.NoError(t, tree.SaveFile(path, kdtree.JSONCodec[types.Tensor3D]{}, kdtree.ContainerOptions{}))
+
+	// Both the header and the payload claim 3 dimensions, but the JSON codec decodes 2D values.
+	_, _, err := kdtree.LoadFile(path, kdtree.JSONCodec[types.Tensor2D]{})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidEncoding)
+	opts := kdtree.DefaultDecodeOptions
+	opts.Dimensions = dimensions2DCount
+	_, _, err = kdtree.LoadFileWithOptions(path, kdtree.JSONCodec[types.Tensor2D]{}, opts)
+	assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch)
+
+	opts.Dimensions = dimensions3DCount
+	loaded, _, err := kdtree.LoadFileWithOptions(path, kdtree.JSONCodec[types.Tensor3D]{}, opts)
+	assert.NoError(t, err)
+	assert.True(t, loaded.Equal(tree))
+}
+
+// reverseCompressor stores the data reversed, which is enough to check that custom compressors are used
+// in both directions.
+type reverseCompressor struct{}
+
+func (reverseCompressor) Name() string {
+	return "reverse"
+}
+
+type reverseWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (w *reverseWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *reverseWriter) Close() error {
+	slices.Reverse(w.buf)
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+func (reverseCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return &reverseWriter{w: w}, nil
+}
+
+func (reverseCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := io.ReadAll(r)
+	slices.Reverse(b)
+	return io.NopCloser(bytes.NewReader(b)), err
+}
+
+func TestContainerCompression(t *testing.T) {
+	var ps []types.Tensor2D
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 40; y++ {
+			ps = append(ps, types.Tensor2D{x, y})
+		}
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	plain, err := tree.MarshalContainer(types.Tensor2DFixedCodec{}, kdtree.ContainerOptions{})
+	assert.NoError(t, err)
+
+	for _, compressor := range []kdtree.Compressor{
+		kdtree.GzipCompressor{},
+		kdtree.FlateCompressor{Level: flate.BestCompression},
+	} {
+		b, err := tree.MarshalContainer(types.Tensor2DFixedCodec{}, kdtree.ContainerOptions{Compressor: compressor})
+		assert.NoError(t, err)
+		assert.Less(t, 2*len(b), len(plain))
+		decoded, header, err := kdtree.UnmarshalContainer(b, types.Tensor2DFixedCodec{})
+		assert.NoError(t, err)
+		assert.Equal(t, compressor.Name(), header.Compression)
+		assert.True(t, decoded.SameSet(tree))
+
+		opts := kdtree.DefaultDecodeOptions
+		opts.MaxBytes = len(plain) / 2
+		_, _, err = kdtree.UnmarshalContainerWithOptions(b, types.Tensor2DFixedCodec{}, opts)
+		assert.ErrorIs(t, err, kdtree.ErrInvalidContainer)
+		assert.ErrorContains(t, err, "decompressed tree exceeds the limit")
+	}
+
+	b, err := tree.MarshalContainer(types.Tensor2DFixedCodec{}, kdtree.ContainerOptions{Compressor: reverseCompressor{}})
+	assert.NoError(t, err)
+	_, _, err = kdtree.UnmarshalContainer(b, types.Tensor2DFixedCodec{})
+	assert.ErrorContains(t, err, `no compressor registered for "reverse"`)
+	kdtree.RegisterCompressor(reverseCompressor{})
+	decoded, _, err := kdtree.UnmarshalContainer(b, types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decoded.SameSet(tree))
+
+	// Containers written before compression was supported have version 1 and no compressor name.
+	header, err := kdtree.ReadContainerHeader(plain)
+	assert.NoError(t, err)
+	compressionField := 4 + 2 + 4 + 8 + 8 + 2 + len(header.Codec)
+	v1 := slices.Delete(slices.Clone(plain[:len(plain)-4]), compressionField, compressionField+2)
+	v1[4] = 1
+	v1 = binary.LittleEndian.AppendUint32(v1, crc32.Checksum(v1, crc32.MakeTable(crc32.Castagnoli)))
+	decoded, header, err = kdtree.UnmarshalContainer(v1, types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, header.Version)
+	assert.True(t, decoded.SameSet(tree))
+}
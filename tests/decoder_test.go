@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	types "github.com/rishitc/go-kd-tree/internal/types"
+	"github.com/rishitc/go-kd-tree/points"
+	"github.com/stretchr/testify/assert"
+)
+
+var decoderOptions = kdtree.DecodeOptions{
+	Dimensions:   dimensions2DCount,
+	MaxBytes:     1 << 16,
+	MaxItems:     1 << 10,
+	MaxItemBytes: 1 << 8,
+}
+
+func encodedDecoderSeeds(t testing.TB) [][]byte {
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, []types.Tensor2D{{3, 2}, {5, 8}, {6, 1}, {9, 0}, {4, 4}})
+	var seeds [][]byte
+	for _, codec := range []kdtree.Codec[types.Tensor2D]{types.Tensor2DFixedCodec{}, types.Tensor2DVarintCodec{}, types.Tensor2DDeltaCodec{}} {
+		b, err := tree.EncodeWith(codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeds = append(seeds, b)
+	}
+	vecs := kdtree.NewKDTreeWithValues(dimensions2DCount, []points.VecF64{{3, 2}, {5, 8}, {6, 1}})
+	b, err := vecs.EncodeWith(points.VecF64Codec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(seeds, b, mixedLengthVectors(t, dimensions2DCount), mixedLengthVectors(t, dimensions3DCount))
+}
+
+// mixedLengthVectors encodes a tree with d dimensions of the values {1, 2} and {1, 2, 3}, which share
+// their first two coordinates.
+func mixedLengthVectors(t testing.TB, d int) []byte {
+	tree := kdtree.NewKDTreeWithValues(d, []points.VecF64{{1, 2, 3}, {1, 2, 4}})
+	b, err := tree.EncodeWith(kdtree.FuncCodec[points.VecF64]{
+		EncodeFunc: func(v points.VecF64) ([]byte, error) {
+			if v[2] == 4 {
+				v = v[:2]
+			}
+			return points.VecF64Codec{}.Encode(v)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// decodeUntrusted decodes b with every layout of the codecs and with vectors of any length, with and
+// without an expected number of dimensions, and checks that whatever is decoded is a valid tree.
+func decodeUntrusted(t *testing.T, b []byte) {
+	for _, codec := range []kdtree.Codec[types.Tensor2D]{types.Tensor2DFixedCodec{}, types.Tensor2DVarintCodec{}, types.Tensor2DDeltaCodec{}} {
+		decodeAndValidate(t, b, codec)
+	}
+	decodeAndValidate[points.VecF64](t, b, points.VecF64Codec{})
+}
+
+func decodeAndValidate[T kdtree.Comparable[T]](t *testing.T, b []byte, codec kdtree.Codec[T]) {
+	for _, opts := range []kdtree.DecodeOptions{decoderOptions, kdtree.DefaultDecodeOptions} {
+		tree, err := kdtree.NewKDTreeFromBytesWithOptions(b, codec, opts)
+		if err != nil {
+			continue
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("decoded an invalid tree: %v", err)
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for _, b := range encodedDecoderSeeds(t) {
+		for n := 0; n < len(b); n++ {
+			_, err := kdtree.NewKDTreeFromBytesWithOptions(b[:n], types.Tensor2DFixedCodec{}, decoderOptions)
+			assert.Error(t, err, "truncated to %d bytes", n)
+		}
+		for i := range b {
+			corrupt := append([]byte(nil), b...)
+			corrupt[i] ^= 0xff
+			decodeUntrusted(t, corrupt)
+		}
+	}
+
+	b := encodedDecoderSeeds(t)[0]
+	_, err := kdtree.NewKDTreeFromBytesWithOptions(b, types.Tensor2DFixedCodec{}, kdtree.DecodeOptions{MaxItems: 4})
+	assert.True(t, errors.Is(err, kdtree.ErrInvalidEncoding))
+	_, err = kdtree.NewKDTreeFromBytesWithOptions(b, types.Tensor2DFixedCodec{}, kdtree.DecodeOptions{MaxBytes: len(b) - 1})
+	assert.True(t, errors.Is(err, kdtree.ErrInvalidEncoding))
+	_, err = kdtree.NewKDTreeFromBytesWithOptions(b, types.Tensor2DFixedCodec{}, kdtree.DecodeOptions{Dimensions: 3})
+	assert.True(t, errors.Is(err, kdtree.ErrInvalidEncoding))
+	tree, err := kdtree.NewKDTreeFromBytesWithOptions(b, types.Tensor2DFixedCodec{}, decoderOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, tree.Len())
+}
+
+func TestDecodeDimensionMismatch(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(dimensions3DCount, []types.Tensor3D{{3, 2, 1}, {5, 8, 0}, {6, 1, 7}})
+	b, err := tree.EncodeWith(kdtree.JSONCodec[types.Tensor3D]{})
+	assert.NoError(t, err)
+
+	// The JSON codec drops the third coordinate of each value, leaving 2D values in a tree that claims
+	// to have 3 dimensions.
+	_, err = kdtree.NewKDTreeFromBytesWith(b, kdtree.JSONCodec[types.Tensor2D]{})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidEncoding)
+	_, err = kdtree.NewKDTreeFromBytesWithOptions(b, kdtree.JSONCodec[types.Tensor2D]{}, decoderOptions)
+	assert.ErrorIs(t, err, kdtree.ErrInvalidEncoding)
+
+	for _, d := range []int{dimensions2DCount, dimensions3DCount} {
+		_, err = kdtree.NewKDTreeFromBytesWith(mixedLengthVectors(t, d), points.VecF64Codec{})
+		assert.ErrorIs(t, err, kdtree.ErrInvalidEncoding)
+		assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, b := range encodedDecoderSeeds(f) {
+		f.Add(b)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{4, 0, 0, 0, 0xfc, 0xff, 0xff, 0xff})
+	f.Fuzz(decodeUntrusted)
+}
@@ -1,6 +1,11 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
 	"slices"
 	"testing"
 
@@ -327,8 +332,8 @@ func Test2DFindMin1(t *testing.T) {
 		},
 	}
 	for _, v := range testTable {
-		nn, ok := tree.FindMin(v.input)
-		if !ok || !slices.Equal(nn[:], v.expected[:]) {
+		nn, err := tree.FindMin(v.input)
+		if err != nil || !slices.Equal(nn[:], v.expected[:]) {
 			t.Fatalf("Expected closest point: %v, got %v", v.expected, nn)
 		}
 	}
@@ -357,8 +362,8 @@ func Test2DFindMin2(t *testing.T) {
 		},
 	}
 	for _, v := range testTable {
-		nn, ok := tree.FindMin(v.input)
-		if !ok || !slices.Equal(nn[:], v.expected[:]) {
+		nn, err := tree.FindMin(v.input)
+		if err != nil || !slices.Equal(nn[:], v.expected[:]) {
 			t.Fatalf("Expected closest point: %v, got %v", v.expected, nn)
 		}
 	}
@@ -391,8 +396,8 @@ func Test2DFindMax1(t *testing.T) {
 		},
 	}
 	for _, v := range testTable {
-		nn, ok := tree.FindMax(v.input)
-		if !ok || !slices.Equal(nn[:], v.expected[:]) {
+		nn, err := tree.FindMax(v.input)
+		if err != nil || !slices.Equal(nn[:], v.expected[:]) {
 			t.Fatalf("Expected closest point: %v, got %v", v.expected, nn)
 		}
 	}
@@ -421,8 +426,8 @@ func Test2DFindMax2(t *testing.T) {
 		},
 	}
 	for _, v := range testTable {
-		nn, ok := tree.FindMin(v.input)
-		if !ok || !slices.Equal(nn[:], v.expected[:]) {
+		nn, err := tree.FindMin(v.input)
+		if err != nil || !slices.Equal(nn[:], v.expected[:]) {
 			t.Fatalf("Expected closest point: %v, got %v", v.expected, nn)
 		}
 	}
@@ -662,3 +667,967 @@ func Test2DDot(t *testing.T) {
 		})
 	}
 }
+
+func Test2DRemoveRange(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	removed := tree.RemoveRange(func(td types.Tensor2D, i int) kdtree.RelativePosition {
+		switch i {
+		case -1:
+			if x, y := td[0], td[1]; 2 <= x && x < 6 && 0 <= y && y < 5 {
+				return kdtree.InRange
+			}
+			return kdtree.AfterRange
+		case 0:
+			if x := td[0]; x < 2 {
+				return kdtree.BeforeRange
+			} else if x >= 6 {
+				return kdtree.AfterRange
+			}
+			return kdtree.InRange
+		case 1:
+			if y := td[1]; y < 0 {
+				return kdtree.BeforeRange
+			} else if y >= 5 {
+				return kdtree.AfterRange
+			}
+			return kdtree.InRange
+		}
+		return kdtree.AfterRange
+	})
+	expected := []types.Tensor2D{{1, 1}, {5, 8}, {6, 1}, {8, 7}, {9, 0}}
+	assert.Equal(t, 3, removed)
+	got := tree.Values()
+	slices.SortFunc(got, tensor2DSortFunc)
+	assert.Equal(t, expected, got)
+	for _, v := range expected {
+		nn, ok := tree.NearestNeighbor(v)
+		if !ok || !slices.Equal(nn[:], v[:]) {
+			t.Fatalf("Expected closest point: %v, got %v", v, nn)
+		}
+	}
+}
+
+func Test2DRemoveWhere(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	removed := tree.RemoveWhere(func(td types.Tensor2D) bool {
+		return (td[0]+td[1])%2 == 0
+	})
+	expected := []types.Tensor2D{{3, 2}, {5, 8}, {6, 1}, {9, 0}, {8, 7}}
+	slices.SortFunc(expected, tensor2DSortFunc)
+	assert.Equal(t, 3, removed)
+	got := tree.Values()
+	slices.SortFunc(got, tensor2DSortFunc)
+	assert.Equal(t, expected, got)
+	nn, ok := tree.NearestNeighbor(types.Tensor2D{2, 2})
+	if !ok || !slices.Equal(nn[:], []int{3, 2}) {
+		t.Fatalf("Expected closest point: %v, got %v", types.Tensor2D{3, 2}, nn)
+	}
+}
+
+func Test2DLazyDelete(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	tree.SetLazyDelete(true, 0)
+	for _, v := range []types.Tensor2D{{4, 4}, {1, 1}, {6, 1}} {
+		if !tree.Remove(v) {
+			t.Fatalf("Expected %v to be removed", v)
+		}
+	}
+	if tree.Remove(types.Tensor2D{4, 4}) {
+		t.Fatalf("Expected %v to already be removed", types.Tensor2D{4, 4})
+	}
+
+	expected := []types.Tensor2D{{2, 2}, {3, 2}, {5, 8}, {8, 7}, {9, 0}}
+	got := tree.Values()
+	slices.SortFunc(got, tensor2DSortFunc)
+	assert.Equal(t, expected, got)
+
+	nn, ok := tree.NearestNeighbor(types.Tensor2D{4, 4})
+	if !ok || !slices.Equal(nn[:], []int{3, 2}) {
+		t.Fatalf("Expected closest point: %v, got %v", types.Tensor2D{3, 2}, nn)
+	}
+	nn, ok = tree.NearestNeighbor(types.Tensor2D{6, 1})
+	if !ok || !slices.Equal(nn[:], []int{9, 0}) && !slices.Equal(nn[:], []int{3, 2}) {
+		t.Fatalf("Expected closest point: %v or %v, got %v", types.Tensor2D{9, 0}, types.Tensor2D{3, 2}, nn)
+	}
+	minX, err := tree.FindMin(0)
+	if err != nil || !slices.Equal(minX[:], []int{2, 2}) {
+		t.Fatalf("Expected minimum point: %v, got %v", types.Tensor2D{2, 2}, minX)
+	}
+	nns := tree.KNN(types.Tensor2D{1, 1}, 2)
+	slices.SortFunc(nns, tensor2DSortFunc)
+	assert.Equal(t, []types.Tensor2D{{2, 2}, {3, 2}}, nns)
+
+	tree.Insert(types.Tensor2D{1, 1})
+	nn, ok = tree.NearestNeighbor(types.Tensor2D{0, 0})
+	if !ok || !slices.Equal(nn[:], []int{1, 1}) {
+		t.Fatalf("Expected closest point: %v, got %v", types.Tensor2D{1, 1}, nn)
+	}
+
+	tree.Compact()
+	expected = []types.Tensor2D{{1, 1}, {2, 2}, {3, 2}, {5, 8}, {8, 7}, {9, 0}}
+	got = tree.Values()
+	slices.SortFunc(got, tensor2DSortFunc)
+	assert.Equal(t, expected, got)
+	assert.NotContains(t, tree.String(), "deleted")
+}
+
+func Test2DCloneEqualSameSet(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	clone := tree.Clone()
+	if !tree.Equal(clone) || !tree.SameSet(clone) {
+		t.Fatalf("Expected clone to be equal to the tree\nExpected:\n%s\nGot:\n%s", tree, clone)
+	}
+
+	tree.Remove(types.Tensor2D{4, 4})
+	if tree.Equal(clone) || tree.SameSet(clone) {
+		t.Fatalf("Expected clone to be unaffected by changes to the tree\nTree:\n%s\nClone:\n%s", tree, clone)
+	}
+	tree.Insert(types.Tensor2D{4, 4})
+	if tree.Equal(clone) {
+		t.Fatalf("Expected the tree structure to differ from the clone\nTree:\n%s\nClone:\n%s", tree, clone)
+	}
+	if !tree.SameSet(clone) {
+		t.Fatalf("Expected the tree to hold the same values as the clone\nTree:\n%s\nClone:\n%s", tree, clone)
+	}
+
+	clone.Clear()
+	assert.Empty(t, clone.Values())
+	if _, ok := clone.NearestNeighbor(types.Tensor2D{4, 4}); ok {
+		t.Fatalf("Expected no nearest neighbor in a cleared tree")
+	}
+	assert.Len(t, tree.Values(), len(ps))
+}
+
+func Test2DMerge(t *testing.T) {
+	ps1 := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+	}
+	ps2 := []types.Tensor2D{
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+		{6, 1},
+	}
+	tests := []struct {
+		name string
+		lhs  []types.Tensor2D
+		rhs  []types.Tensor2D
+	}{
+		{
+			name: "Trees of a similar size",
+			lhs:  ps1,
+			rhs:  ps2,
+		},
+		{
+			name: "A small tree is inserted into the larger one",
+			lhs:  append(slices.Clone(ps1), ps2[:4]...),
+			rhs:  []types.Tensor2D{{7, 7}, {3, 2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lhs := kdtree.NewKDTreeWithValues(dimensions2DCount, slices.Clone(test.lhs))
+			rhs := kdtree.NewKDTreeWithValues(dimensions2DCount, slices.Clone(test.rhs))
+			merged, err := kdtree.Merge(lhs, rhs)
+			assert.NoError(t, err)
+
+			var union []types.Tensor2D
+			for _, v := range append(slices.Clone(test.lhs), test.rhs...) {
+				if !slices.Contains(union, v) {
+					union = append(union, v)
+				}
+			}
+			expected := kdtree.NewKDTreeWithValues(dimensions2DCount, union)
+			if !merged.SameSet(expected) {
+				t.Fatalf("Merged tree does not hold the expected values\nExpected:\n%s\nGot:\n%s", expected, merged)
+			}
+			assert.NoError(t, merged.Validate())
+		})
+	}
+
+	// Trees over disjoint halves of the space leave the merged tree unbalanced until it is rebuilt.
+	var left, right []types.Tensor2D
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 16; y++ {
+			left = append(left, types.Tensor2D{x, y})
+			right = append(right, types.Tensor2D{x, y + 16})
+		}
+	}
+	merged, err := kdtree.Merge(
+		kdtree.NewKDTreeWithValues(dimensions2DCount, left),
+		kdtree.NewKDTreeWithValues(dimensions2DCount, right),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, merged.Validate())
+	assert.Equal(t, len(left)+len(right), merged.Len())
+	assert.LessOrEqual(t, merged.Stats().Imbalance, 1.5)
+
+	_, err = kdtree.Merge(
+		kdtree.NewKDTreeWithValues(dimensions2DCount, ps1),
+		kdtree.NewKDTreeWithValues(1, []types.Tensor2D{{1, 1}}),
+	)
+	assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch)
+}
+
+func Test2DSplit(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	tests := []struct {
+		name       string
+		dim        int
+		pivot      types.Tensor2D
+		expectedLo []types.Tensor2D
+		expectedHi []types.Tensor2D
+	}{
+		{
+			name:       "Split on x",
+			dim:        0,
+			pivot:      types.Tensor2D{5, 0},
+			expectedLo: []types.Tensor2D{{1, 1}, {2, 2}, {3, 2}, {4, 4}},
+			expectedHi: []types.Tensor2D{{5, 8}, {6, 1}, {8, 7}, {9, 0}},
+		},
+		{
+			name:       "Split on y",
+			dim:        1,
+			pivot:      types.Tensor2D{0, 2},
+			expectedLo: []types.Tensor2D{{1, 1}, {6, 1}, {9, 0}},
+			expectedHi: []types.Tensor2D{{2, 2}, {3, 2}, {4, 4}, {5, 8}, {8, 7}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lo, hi, err := tree.Split(test.dim, test.pivot)
+			assert.NoError(t, err)
+			los, his := lo.Values(), hi.Values()
+			slices.SortFunc(los, tensor2DSortFunc)
+			slices.SortFunc(his, tensor2DSortFunc)
+			assert.Equal(t, test.expectedLo, los)
+			assert.Equal(t, test.expectedHi, his)
+		})
+	}
+
+	_, _, err := tree.Split(2, types.Tensor2D{0, 0})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+}
+
+func Test2DStats(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, []types.Tensor2D{})
+	assert.Equal(t, kdtree.TreeStats[types.Tensor2D]{}, tree.Stats())
+
+	tree = kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	expected := kdtree.TreeStats[types.Tensor2D]{
+		Nodes:          8,
+		Height:         4,
+		MinLeafDepth:   2,
+		AvgLeafDepth:   2.25,
+		MaxLeafDepth:   3,
+		LevelOccupancy: []int{1, 2, 4, 1},
+		Imbalance:      1,
+		MinBounds:      []types.Tensor2D{{1, 1}, {9, 0}},
+		MaxBounds:      []types.Tensor2D{{9, 0}, {5, 8}},
+	}
+	assert.Equal(t, expected, tree.Stats())
+}
+
+func Test2DQueryStats(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+
+	var stats kdtree.QueryStats
+	nns := tree.KNNWithStats(types.Tensor2D{0, 0}, len(ps), &stats)
+	assert.Len(t, nns, len(ps))
+	assert.Equal(t, kdtree.QueryStats{
+		NodesVisited:        len(ps),
+		DistanceEvaluations: len(ps),
+		BranchesPruned:      0,
+		MaxDepth:            3,
+	}, stats)
+
+	stats = kdtree.QueryStats{}
+	nn, ok := tree.NearestNeighborWithStats(types.Tensor2D{0, 0}, &stats)
+	if !ok || !slices.Equal(nn[:], []int{1, 1}) {
+		t.Fatalf("Expected closest point: %v, got %v", types.Tensor2D{1, 1}, nn)
+	}
+	assert.Equal(t, kdtree.QueryStats{
+		NodesVisited:        3,
+		DistanceEvaluations: 3,
+		BranchesPruned:      2,
+		MaxDepth:            2,
+	}, stats)
+
+	stats = kdtree.QueryStats{}
+	res := tree.RangeSearchWithStats(func(td types.Tensor2D, i int) kdtree.RelativePosition {
+		switch i {
+		case -1:
+			if x, y := td[0], td[1]; 0 <= x && x < 3 && 0 <= y && y < 3 {
+				return kdtree.InRange
+			}
+			return kdtree.AfterRange
+		case 0:
+			if x := td[0]; x < 0 {
+				return kdtree.BeforeRange
+			} else if x >= 3 {
+				return kdtree.AfterRange
+			}
+			return kdtree.InRange
+		case 1:
+			if y := td[1]; y < 0 {
+				return kdtree.BeforeRange
+			} else if y >= 3 {
+				return kdtree.AfterRange
+			}
+			return kdtree.InRange
+		}
+		return kdtree.AfterRange
+	}, &stats)
+	slices.SortFunc(res, tensor2DSortFunc)
+	assert.Equal(t, []types.Tensor2D{{1, 1}, {2, 2}}, res)
+	assert.Equal(t, kdtree.QueryStats{
+		NodesVisited:        4,
+		DistanceEvaluations: 0,
+		BranchesPruned:      1,
+		MaxDepth:            2,
+	}, stats)
+}
+
+func Test2DSVG(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, []types.Tensor2D{{2, 2}, {1, 1}, {3, 4}})
+	coordinate := func(v types.Tensor2D, dim int) float64 {
+		return float64(v[dim])
+	}
+	query := types.Tensor2D{3, 3}
+	tests := []struct {
+		name     string
+		input    kdtree.SVGOptions[types.Tensor2D]
+		expected string
+	}{
+		{
+			name: "Nearest neighbor of a query",
+			input: kdtree.SVGOptions[types.Tensor2D]{
+				Coordinate: coordinate,
+				Width:      100,
+				Height:     100,
+				Bounds:     &[4]float64{0, 0, 5, 5},
+				Query:      &query,
+				K:          1,
+			},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <rect x="0.00" y="0.00" width="100.00" height="100.00" fill="white" stroke="black"/>
+  <line x1="40.00" y1="100.00" x2="40.00" y2="0.00" stroke="#d62728"/>
+  <line x1="0.00" y1="80.00" x2="40.00" y2="80.00" stroke="#1f77b4"/>
+  <line x1="40.00" y1="20.00" x2="100.00" y2="20.00" stroke="#1f77b4"/>
+  <circle cx="40.00" cy="60.00" r="3" fill="black"/>
+  <circle cx="20.00" cy="80.00" r="3" fill="black"/>
+  <circle cx="60.00" cy="20.00" r="3" fill="black"/>
+  <line x1="60.00" y1="40.00" x2="60.00" y2="20.00" stroke="#ff7f0e" stroke-dasharray="4"/>
+  <circle cx="60.00" cy="20.00" r="3" fill="#2ca02c"/>
+  <circle cx="60.00" cy="40.00" r="4" fill="#ff7f0e"/>
+</svg>
+`,
+		},
+		{
+			name: "Cells visited by a range search",
+			input: kdtree.SVGOptions[types.Tensor2D]{
+				Coordinate: coordinate,
+				Width:      100,
+				Height:     100,
+				Bounds:     &[4]float64{0, 0, 5, 5},
+				Range: func(td types.Tensor2D, i int) kdtree.RelativePosition {
+					switch i {
+					case -1:
+						if x := td[0]; x >= 3 {
+							return kdtree.InRange
+						}
+						return kdtree.AfterRange
+					case 0:
+						if x := td[0]; x < 3 {
+							return kdtree.BeforeRange
+						}
+						return kdtree.InRange
+					}
+					return kdtree.InRange
+				},
+			},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <rect x="0.00" y="0.00" width="100.00" height="100.00" fill="white" stroke="black"/>
+  <rect x="0.00" y="0.00" width="100.00" height="100.00" fill="#ffe08a" fill-opacity="0.5" stroke="none"/>
+  <rect x="40.00" y="0.00" width="60.00" height="100.00" fill="#ffe08a" fill-opacity="0.5" stroke="none"/>
+  <line x1="40.00" y1="100.00" x2="40.00" y2="0.00" stroke="#d62728"/>
+  <line x1="0.00" y1="80.00" x2="40.00" y2="80.00" stroke="#1f77b4"/>
+  <line x1="40.00" y1="20.00" x2="100.00" y2="20.00" stroke="#1f77b4"/>
+  <circle cx="40.00" cy="60.00" r="3" fill="black"/>
+  <circle cx="20.00" cy="80.00" r="3" fill="black"/>
+  <circle cx="60.00" cy="20.00" r="3" fill="black"/>
+  <circle cx="60.00" cy="20.00" r="3" fill="#2ca02c"/>
+</svg>
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svg, err := tree.SVG(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, svg)
+		})
+	}
+
+	_, err := kdtree.NewKDTreeWithValues(3, []types.Tensor3D{{1, 2, 3}}).SVG(kdtree.SVGOptions[types.Tensor3D]{
+		Coordinate: func(v types.Tensor3D, dim int) float64 {
+			return float64(v[dim])
+		},
+	})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+}
+
+func Test2DDotWithOptions(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	query := types.Tensor2D{0, 0}
+	tests := []struct {
+		name     string
+		input    kdtree.DotOptions[types.Tensor2D]
+		expected string
+	}{
+		{
+			name: "Truncated subtrees with split dimensions",
+			input: kdtree.DotOptions[types.Tensor2D]{
+				MaxDepth:           1,
+				ShowSplitDimension: true,
+			},
+			expected: `digraph BST {
+    node0 [label="[4, 4]\ndim 0"]
+    node1 [label="[2, 2]\ndim 1"];
+    node0 -> node1;
+    node2 [label="… 1 nodes", shape=box, style=dotted];
+    node1 -> node2;
+    node3 [label="… 1 nodes", shape=box, style=dotted];
+    node1 -> node3;
+    node4 [label="[6, 1]\ndim 1"];
+    node0 -> node4;
+    node5 [label="… 1 nodes", shape=box, style=dotted];
+    node4 -> node5;
+    node6 [label="… 2 nodes", shape=box, style=dotted];
+    node4 -> node6;
+}
+`,
+		},
+		{
+			name: "Highlighted nearest neighbor query without nil leaves",
+			input: kdtree.DotOptions[types.Tensor2D]{
+				OmitNilLeaves:  true,
+				HighlightQuery: &query,
+			},
+			expected: `digraph BST {
+    node0 [label="[4, 4]", color=red, fontcolor=red]
+    node1 [label="[2, 2]", color=red, fontcolor=red];
+    node0 -> node1 [color=red];
+    node2 [label="[1, 1]", color=red, fontcolor=red];
+    node1 -> node2 [color=red];
+    node3 [label="[3, 2]"];
+    node1 -> node3;
+    node4 [label="[6, 1]"];
+    node0 -> node4;
+    node5 [label="[9, 0]"];
+    node4 -> node5;
+    node6 [label="[5, 8]"];
+    node4 -> node6;
+    node7 [label="[8, 7]"];
+    node6 -> node7;
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+			assert.Equal(t, test.expected, tree.DotWithOptions(test.input))
+		})
+	}
+}
+
+func Test2DWalk(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+		{1, 1},
+		{2, 2},
+		{8, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	tests := []struct {
+		name     string
+		order    kdtree.TraversalOrder
+		limit    int
+		expected []types.Tensor2D
+	}{
+		{
+			name:     "Preorder",
+			order:    kdtree.PreOrder,
+			expected: []types.Tensor2D{{4, 4}, {2, 2}, {1, 1}, {3, 2}, {6, 1}, {9, 0}, {5, 8}, {8, 7}},
+		},
+		{
+			name:     "Inorder",
+			order:    kdtree.InOrder,
+			expected: []types.Tensor2D{{1, 1}, {2, 2}, {3, 2}, {4, 4}, {9, 0}, {6, 1}, {5, 8}, {8, 7}},
+		},
+		{
+			name:     "Postorder",
+			order:    kdtree.PostOrder,
+			expected: []types.Tensor2D{{1, 1}, {3, 2}, {2, 2}, {9, 0}, {8, 7}, {5, 8}, {6, 1}, {4, 4}},
+		},
+		{
+			name:     "Level order",
+			order:    kdtree.LevelOrder,
+			expected: []types.Tensor2D{{4, 4}, {2, 2}, {6, 1}, {1, 1}, {3, 2}, {9, 0}, {5, 8}, {8, 7}},
+		},
+		{
+			name:     "Early stop",
+			order:    kdtree.InOrder,
+			limit:    3,
+			expected: []types.Tensor2D{{1, 1}, {2, 2}, {3, 2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []types.Tensor2D
+			err := tree.Walk(test.order, func(n kdtree.NodeInfo[types.Tensor2D]) bool {
+				got = append(got, n.Value)
+				return test.limit == 0 || len(got) < test.limit
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	var leaf kdtree.NodeInfo[types.Tensor2D]
+	tree.Walk(kdtree.PreOrder, func(n kdtree.NodeInfo[types.Tensor2D]) bool {
+		leaf = n
+		return !slices.Equal(n.Value[:], []int{8, 7})
+	})
+	assert.Equal(t, kdtree.NodeInfo[types.Tensor2D]{
+		Value:          types.Tensor2D{8, 7},
+		Depth:          3,
+		SplitDimension: 1,
+	}, leaf)
+
+	err := tree.Walk(kdtree.LevelOrder+1, func(kdtree.NodeInfo[types.Tensor2D]) bool {
+		t.Fatal("Walk visited a node with an invalid order")
+		return false
+	})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidTraversalOrder)
+}
+
+func Test2DJSON(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	tree.Insert(types.Tensor2D{7, 0})
+
+	b, err := json.Marshal(tree)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dimensions": 2,
+		"root": {
+			"value": [5, 8],
+			"dimension": 0,
+			"left": {"value": [3, 2], "dimension": 1, "left": null, "right": null},
+			"right": {
+				"value": [6, 1],
+				"dimension": 1,
+				"left": {"value": [7, 0], "dimension": 0, "left": null, "right": null},
+				"right": null
+			}
+		}
+	}`, string(b))
+
+	var decoded kdtree.KDTree[types.Tensor2D]
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	if !decoded.Equal(tree) {
+		t.Fatalf("Tree does not match expected tree structure\nExpected:\n%s\nGot:\n%s", tree, &decoded)
+	}
+
+	opts := kdtree.JSONOptions[types.Tensor2D]{
+		EncodeItem: func(v types.Tensor2D) ([]byte, error) {
+			return json.Marshal(fmt.Sprintf("%d,%d", v[0], v[1]))
+		},
+		DecodeItem: func(b []byte) (types.Tensor2D, error) {
+			var s string
+			var v types.Tensor2D
+			if err := json.Unmarshal(b, &s); err != nil {
+				return v, err
+			}
+			_, err := fmt.Sscanf(s, "%d,%d", &v[0], &v[1])
+			return v, err
+		},
+		PointsOnly: true,
+	}
+	b, err = tree.MarshalJSONWith(opts)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"dimensions": 2, "points": ["5,8", "3,2", "6,1", "7,0"]}`, string(b))
+	assert.NoError(t, decoded.UnmarshalJSONWith(b, opts))
+	if !decoded.SameSet(tree) {
+		t.Fatalf("Tree does not hold the expected values\nExpected:\n%s\nGot:\n%s", tree, &decoded)
+	}
+
+	err = decoded.UnmarshalJSON([]byte(`{"dimensions": 2, "root": {"value": [5, 8], "dimension": 0, "left": {"value": [6, 1], "dimension": 1}}}`))
+	assert.ErrorIs(t, err, kdtree.ErrInvalidTree)
+}
+
+func Test2DJSONLazyDeleted(t *testing.T) {
+	tree, err := kdtree.New(dimensions2DCount,
+		kdtree.WithValues([]types.Tensor2D{{1, 1}, {2, 2}, {3, 3}, {4, 4}, {5, 5}}),
+		kdtree.WithLazyDelete[types.Tensor2D](0),
+	)
+	assert.NoError(t, err)
+	assert.True(t, tree.Remove(types.Tensor2D{4, 4}))
+	b, err := json.Marshal(tree)
+	assert.NoError(t, err)
+
+	var eager kdtree.KDTree[types.Tensor2D]
+	assert.NoError(t, json.Unmarshal(b, &eager))
+	assert.Equal(t, 4, eager.Len())
+	assert.False(t, eager.Remove(types.Tensor2D{4, 4}))
+	assert.True(t, eager.Remove(types.Tensor2D{3, 3}))
+	assert.Equal(t, 3, eager.Len())
+	assert.NoError(t, eager.Validate())
+	assert.ElementsMatch(t, []types.Tensor2D{{1, 1}, {2, 2}, {5, 5}}, eager.Values())
+
+	lazy, err := kdtree.New(dimensions2DCount, kdtree.WithLazyDelete[types.Tensor2D](0))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(b, lazy))
+	assert.True(t, lazy.Equal(tree))
+	assert.False(t, lazy.Remove(types.Tensor2D{4, 4}))
+	assert.NoError(t, lazy.Validate())
+}
+
+func Test2DJSONDimensionMismatch(t *testing.T) {
+	inputs := []string{
+		`{"dimensions": 3, "points": [[1, 2], [3, 4]]}`,
+		`{"dimensions": 3, "root": {"value": [3, 4], "dimension": 0, "left": {"value": [1, 2], "dimension": 1}}}`,
+	}
+	for _, input := range inputs {
+		var decoded kdtree.KDTree[types.Tensor2D]
+		err := decoded.UnmarshalJSON([]byte(input))
+		assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch, input)
+
+		tree, _ := kdtree.New[types.Tensor2D](dimensions2DCount)
+		assert.ErrorIs(t, tree.UnmarshalJSON([]byte(input)), kdtree.ErrDimensionMismatch, input)
+
+		err = decoded.UnmarshalJSONWith([]byte(input), kdtree.JSONOptions[types.Tensor2D]{Dimensions: 3})
+		assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch, input)
+	}
+
+	var decoded kdtree.KDTree[types.Tensor2D]
+	err := decoded.UnmarshalJSONWith([]byte(`{"dimensions": 2, "points": [[1, 2]]}`), kdtree.JSONOptions[types.Tensor2D]{Dimensions: 3})
+	assert.ErrorIs(t, err, kdtree.ErrDimensionMismatch)
+}
+
+func Test2DBinaryAndGob(t *testing.T) {
+	type snapshot struct {
+		Name string
+		Tree *kdtree.KDTree[types.Tensor2D]
+	}
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+
+	var decoded kdtree.KDTree[types.Tensor2D]
+	_, err := tree.MarshalBinary()
+	assert.Error(t, err, "Expected an error without a registered codec")
+
+	kdtree.RegisterCodec[types.Tensor2D](kdtree.JSONCodec[types.Tensor2D]{})
+	b, err := tree.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, decoded.UnmarshalBinary(b))
+	if !decoded.SameSet(tree) {
+		t.Fatalf("Tree does not hold the expected values\nExpected:\n%s\nGot:\n%s", tree, &decoded)
+	}
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(snapshot{Name: "tiles", Tree: tree}))
+	var s snapshot
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&s))
+	assert.Equal(t, "tiles", s.Name)
+	if !s.Tree.SameSet(tree) {
+		t.Fatalf("Tree does not hold the expected values\nExpected:\n%s\nGot:\n%s", tree, s.Tree)
+	}
+}
+
+// plainPoint implements Comparable without an Encode method, so trees holding it need a Codec to be
+// encoded.
+type plainPoint struct {
+	x, y int
+}
+
+func (lhs plainPoint) Order(rhs plainPoint, dim int) int {
+	return types.Tensor2D{lhs.x, lhs.y}.Order(types.Tensor2D{rhs.x, rhs.y}, dim)
+}
+
+func (lhs plainPoint) Dist(rhs plainPoint) int {
+	return types.Tensor2D{lhs.x, lhs.y}.Dist(types.Tensor2D{rhs.x, rhs.y})
+}
+
+func (lhs plainPoint) DistDim(rhs plainPoint, dim int) int {
+	return types.Tensor2D{lhs.x, lhs.y}.DistDim(types.Tensor2D{rhs.x, rhs.y}, dim)
+}
+
+func (lhs plainPoint) String() string {
+	return fmt.Sprintf("[%d, %d]", lhs.x, lhs.y)
+}
+
+func Test2DEncodeWithCodec(t *testing.T) {
+	ps := []plainPoint{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{9, 0},
+		{4, 4},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+	codec := kdtree.FuncCodec[plainPoint]{
+		EncodeFunc: func(p plainPoint) ([]byte, error) {
+			return []byte(fmt.Sprintf("%d %d", p.x, p.y)), nil
+		},
+		DecodeFunc: func(b []byte) (plainPoint, error) {
+			var p plainPoint
+			_, err := fmt.Sscanf(string(b), "%d %d", &p.x, &p.y)
+			return p, err
+		},
+	}
+
+	b, err := tree.EncodeWith(codec)
+	assert.NoError(t, err)
+	decoded, err := kdtree.NewKDTreeFromBytesWith(b, codec)
+	assert.NoError(t, err)
+	if !decoded.SameSet(tree) {
+		t.Fatalf("Tree does not hold the expected values\nExpected:\n%s\nGot:\n%s", tree, decoded)
+	}
+
+	_, err = kdtree.NewKDTreeFromBytesWith(b, kdtree.FuncCodec[plainPoint]{
+		DecodeFunc: func([]byte) (plainPoint, error) {
+			return plainPoint{}, fmt.Errorf("corrupt item")
+		},
+	})
+	assert.EqualError(t, err, "corrupt item")
+	assert.Panics(t, func() { tree.Encode() })
+}
+
+func Test2DCompactCodecs(t *testing.T) {
+	ps := []types.Tensor2D{
+		{3, 2},
+		{5, 8},
+		{6, 1},
+		{-9, 0},
+		{4, -4},
+		{1 << 30, 7},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+
+	fixed, err := tree.EncodeWith(types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	varint, err := tree.EncodeWith(types.Tensor2DVarintCodec{})
+	assert.NoError(t, err)
+	jsonBytes, err := tree.EncodeWith(kdtree.JSONCodec[types.Tensor2D]{})
+	assert.NoError(t, err)
+	assert.Less(t, len(fixed), len(jsonBytes))
+	assert.Less(t, len(varint), len(jsonBytes))
+
+	decoded, err := kdtree.NewKDTreeFromBytesWith(fixed, types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decoded.Equal(tree))
+	decoded, err = kdtree.NewKDTreeFromBytesWith(varint, types.Tensor2DVarintCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decoded.Equal(tree))
+
+	_, err = kdtree.NewKDTreeFromBytesWith(fixed, types.Tensor2DVarintCodec{})
+	assert.Error(t, err)
+}
+
+func Test2DDeltaCodec(t *testing.T) {
+	var ps []types.Tensor2D
+	for x := -20; x < 20; x++ {
+		for y := -20; y < 20; y++ {
+			ps = append(ps, types.Tensor2D{1<<30 + x, -(1 << 30) + y})
+		}
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions2DCount, ps)
+
+	delta, err := tree.EncodeWith(types.Tensor2DDeltaCodec{})
+	assert.NoError(t, err)
+	varint, err := tree.EncodeWith(types.Tensor2DVarintCodec{})
+	assert.NoError(t, err)
+	fixed, err := tree.EncodeWith(types.Tensor2DFixedCodec{})
+	assert.NoError(t, err)
+	assert.Less(t, 2*len(delta), len(varint))
+	assert.Less(t, len(delta), len(fixed))
+
+	decoded, err := kdtree.NewKDTreeFromBytesWith(delta, types.Tensor2DDeltaCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decoded.Equal(tree))
+	_, err = kdtree.NewKDTreeFromBytesWith(delta, types.Tensor2DVarintCodec{})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidEncoding)
+
+	// Trees encoded with one table per value can still be decoded with the delta codec.
+	decoded, err = kdtree.NewKDTreeFromBytesWith(varint, types.Tensor2DDeltaCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decoded.Equal(tree))
+
+	empty, err := kdtree.New[types.Tensor2D](dimensions2DCount)
+	assert.NoError(t, err)
+	b, err := empty.EncodeWith(types.Tensor2DDeltaCodec{})
+	assert.NoError(t, err)
+	decoded, err = kdtree.NewKDTreeFromBytesWith(b, types.Tensor2DDeltaCodec{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, decoded.Len())
+}
+
+func Test2DNew(t *testing.T) {
+	_, err := kdtree.New[types.Tensor2D](0)
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+	_, err = kdtree.New(dimensions2DCount, kdtree.WithLazyDelete[types.Tensor2D](2))
+	assert.Error(t, err)
+	_, err = kdtree.New(dimensions2DCount, kdtree.WithPeriods[types.Tensor2D]([]float64{10, 10}))
+	assert.Error(t, err)
+	assert.PanicsWithValue(t, "dimension is out of range for the tree: a tree needs at least one dimension, got 0", func() {
+		kdtree.NewKDTreeWithValues[types.Tensor2D](0, nil)
+	})
+	assert.Panics(t, func() { kdtree.NewKDTreeWithValues(-1, []types.Tensor2D{{1, 2}}) })
+
+	tree, err := kdtree.New(dimensions2DCount,
+		kdtree.WithValues([]types.Tensor2D{{3, 2}, {5, 8}, {3, 2}, {6, 1}}),
+		kdtree.WithLazyDelete[types.Tensor2D](0.5),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, tree.Validate())
+	assert.ElementsMatch(t, []types.Tensor2D{{3, 2}, {5, 8}, {6, 1}}, tree.Values())
+	assert.NoError(t, tree.Insert(types.Tensor2D{1, 1}))
+
+	minY, err := tree.FindMin(1)
+	assert.NoError(t, err)
+	assert.Equal(t, types.Tensor2D{1, 1}, minY)
+	_, err = tree.FindMin(-1)
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+	_, err = tree.FindMax(dimensions2DCount)
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+	_, _, err = tree.Split(-1, types.Tensor2D{})
+	assert.ErrorIs(t, err, kdtree.ErrInvalidDimension)
+	assert.Nil(t, tree.KNN(types.Tensor2D{0, 0}, -1))
+
+	empty, err := kdtree.New[types.Tensor2D](dimensions2DCount)
+	assert.NoError(t, err)
+	_, err = empty.FindMax(0)
+	assert.ErrorIs(t, err, kdtree.ErrEmptyTree)
+
+	var zero kdtree.KDTree[types.Tensor2D]
+	assert.ErrorIs(t, zero.Insert(types.Tensor2D{1, 2}), kdtree.ErrTreeNotSetup)
+	assert.ErrorIs(t, zero.Insert(types.Tensor2D{3, 4}), kdtree.ErrTreeNotSetup)
+	_, err = zero.FindMin(0)
+	assert.ErrorIs(t, err, kdtree.ErrTreeNotSetup)
+	_, err = zero.EncodeWith(kdtree.JSONCodec[types.Tensor2D]{})
+	assert.ErrorIs(t, err, kdtree.ErrTreeNotSetup)
+	_, err = kdtree.Merge(&zero, tree)
+	assert.ErrorIs(t, err, kdtree.ErrTreeNotSetup)
+	_, err = kdtree.Merge(tree, nil)
+	assert.ErrorIs(t, err, kdtree.ErrTreeNotSetup)
+	assert.False(t, tree.Equal(nil))
+	assert.False(t, tree.SameSet(nil))
+
+	assert.Error(t, tree.SetLazyDelete(true, 1.5))
+	assert.Error(t, tree.SetLazyDelete(false, math.NaN()))
+	assert.NoError(t, tree.SetLazyDelete(false, 0))
+	_, ok := zero.NearestNeighbor(types.Tensor2D{1, 2})
+	assert.False(t, ok)
+	assert.Empty(t, zero.Values())
+	zero.Balance()
+}
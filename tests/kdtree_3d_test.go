@@ -6,6 +6,7 @@ import (
 
 	kdtree "github.com/rishitc/go-kd-tree"
 	types "github.com/rishitc/go-kd-tree/internal/types"
+	"github.com/stretchr/testify/assert"
 )
 
 const dimensions3DCount = 3
@@ -137,3 +138,24 @@ func Test3DNearestNeighbor4(t *testing.T) {
 		}
 	}
 }
+
+func Test3DCompactCodecs(t *testing.T) {
+	ps := []types.Tensor3D{
+		{3, 2, -1},
+		{5, 8, 0},
+		{-6, 1, 9},
+		{9, 0, 1 << 30},
+	}
+	tree := kdtree.NewKDTreeWithValues(dimensions3DCount, ps)
+	for _, codec := range []kdtree.Codec[types.Tensor3D]{
+		types.Tensor3DFixedCodec{},
+		types.Tensor3DVarintCodec{},
+		types.Tensor3DDeltaCodec{},
+	} {
+		b, err := tree.EncodeWith(codec)
+		assert.NoError(t, err)
+		decoded, err := kdtree.NewKDTreeFromBytesWith(b, codec)
+		assert.NoError(t, err)
+		assert.True(t, decoded.Equal(tree))
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	"github.com/stretchr/testify/assert"
+)
+
+type city struct {
+	Name string
+	X, Y float64
+}
+
+func cityCoord(c city, dim int) float64 {
+	if dim == 0 {
+		return c.X
+	}
+	return c.Y
+}
+
+func TestByCoords(t *testing.T) {
+	coords := kdtree.ByCoords(2, cityCoord)
+	cities := []city{
+		{"a", 1, 1},
+		{"b", 1, 5},
+		{"c", 1, 3},
+		{"d", 4, 1},
+		{"e", 4, 4},
+		{"f", 7, 2},
+	}
+	tree := kdtree.NewKDTreeWithValues(2, coords.Points(cities))
+	assert.NoError(t, tree.Validate())
+
+	nn, ok := tree.NearestNeighbor(coords.Point(city{X: 1, Y: 3.4}))
+	assert.True(t, ok)
+	assert.Equal(t, "c", nn.Value.Name)
+
+	// Cities sharing an x coordinate are told apart by the super key.
+	assert.True(t, tree.Remove(coords.Point(cities[2])))
+	assert.False(t, tree.Remove(coords.Point(cities[2])))
+	tree.Insert(coords.Point(city{"g", 1, 4}))
+	assert.NoError(t, tree.Validate())
+	nn, _ = tree.NearestNeighbor(coords.Point(city{X: 1, Y: 3.4}))
+	assert.Equal(t, "g", nn.Value.Name)
+	assert.Equal(t, 5.0, nn.Translate(1, 1).Coord(1))
+	assert.Equal(t, 4.0, nn.Coord(1))
+	assert.Equal(t, "{g 1 4} [1, 4]", nn.String())
+
+	codec := coords.Codec(kdtree.JSONCodec[city]{})
+	b, err := tree.EncodeWith(codec)
+	assert.NoError(t, err)
+	decoded, err := kdtree.NewKDTreeFromBytesWith(b, codec)
+	assert.NoError(t, err)
+	assert.True(t, decoded.SameSet(tree))
+	nn, _ = decoded.NearestNeighbor(coords.Point(city{X: 6, Y: 3}))
+	assert.Equal(t, "f", nn.Value.Name)
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	types "github.com/rishitc/go-kd-tree/internal/types"
+	"github.com/rishitc/go-kd-tree/points"
+	"github.com/stretchr/testify/assert"
+)
+
+func wrappedDist(a, b points.Float2D, period float64) float64 {
+	sum := 0.0
+	for i := range a {
+		d := math.Abs(a[i] - b[i])
+		d = math.Min(d, period-d)
+		sum += d * d
+	}
+	return sum
+}
+
+func TestPeriodic(t *testing.T) {
+	const period = 10.0
+	rng := rand.New(rand.NewSource(43))
+	random := func() points.Float2D {
+		return points.Float2D{rng.Float64() * period, rng.Float64() * period}
+	}
+	vs := make([]points.Float2D, 200)
+	for i := range vs {
+		vs[i] = random()
+	}
+	tree := kdtree.NewKDTreeWithValues(2, vs)
+	assert.NoError(t, tree.SetPeriodic([]float64{period, period}))
+
+	for i := 0; i < 100; i++ {
+		q := random()
+		byDist := slices.Clone(vs)
+		slices.SortFunc(byDist, func(a, b points.Float2D) int {
+			return kdtree.FloatDistKey(wrappedDist(q, a, period)) - kdtree.FloatDistKey(wrappedDist(q, b, period))
+		})
+
+		nn, ok := tree.NearestNeighbor(q)
+		assert.True(t, ok)
+		assert.Equal(t, wrappedDist(q, byDist[0], period), wrappedDist(q, nn, period))
+
+		knn := tree.KNN(q, 4)
+		assert.Len(t, knn, 4)
+		for j, v := range knn {
+			assert.Equal(t, wrappedDist(q, byDist[3-j], period), wrappedDist(q, v, period))
+		}
+
+		var want []points.Float2D
+		for _, v := range vs {
+			if wrappedDist(q, v, period) <= 2.25 {
+				want = append(want, v)
+			}
+		}
+		assert.ElementsMatch(t, want, tree.RadiusSearch(q, kdtree.FloatDistKey(2.25)))
+	}
+
+	// A range crossing the right edge also covers the values just past the left edge.
+	inRange := func(v points.Float2D, dim int) kdtree.RelativePosition {
+		bounds := [2][2]float64{{9, 11}, {0, period}}
+		check := func(dim int) kdtree.RelativePosition {
+			if v[dim] < bounds[dim][0] {
+				return kdtree.BeforeRange
+			} else if v[dim] >= bounds[dim][1] {
+				return kdtree.AfterRange
+			}
+			return kdtree.InRange
+		}
+		if dim >= 0 {
+			return check(dim)
+		}
+		for d := range v {
+			if rel := check(d); rel != kdtree.InRange {
+				return rel
+			}
+		}
+		return kdtree.InRange
+	}
+	var want []points.Float2D
+	for _, v := range vs {
+		if v[0] >= 9 || v[0] < 1 {
+			want = append(want, v)
+		}
+	}
+	assert.ElementsMatch(t, want, tree.RangeSearch(inRange))
+
+	assert.NoError(t, tree.SetPeriodic(nil))
+	nn, _ := tree.NearestNeighbor(points.Float2D{0, 0})
+	assert.Equal(t, []points.Float2D{nn}, tree.RadiusSearch(points.Float2D{0, 0}, points.Float2D{0, 0}.Dist(nn)))
+
+	err := tree.SetPeriodic([]float64{period})
+	assert.True(t, errors.Is(err, kdtree.ErrDimensionMismatch))
+	tensors := kdtree.NewKDTreeWithValues(2, []types.Tensor2D{{1, 2}})
+	assert.Error(t, tensors.SetPeriodic([]float64{period, period}))
+}
+
+func wrappedDist3D(a, b points.Float3D, period float64) float64 {
+	sum := 0.0
+	for i := range a {
+		d := math.Abs(a[i] - b[i])
+		d = math.Min(d, period-d)
+		sum += d * d
+	}
+	return sum
+}
+
+func TestPeriodic3DSkipsFarImages(t *testing.T) {
+	const period = 10.0
+	rng := rand.New(rand.NewSource(44))
+	random := func() points.Float3D {
+		return points.Float3D{rng.Float64() * period, rng.Float64() * period, rng.Float64() * period}
+	}
+	vs := make([]points.Float3D, 1000)
+	for i := range vs {
+		vs[i] = random()
+	}
+	periodic, err := kdtree.New(3, kdtree.WithValues(vs), kdtree.WithPeriods[points.Float3D]([]float64{period, period, period}))
+	assert.NoError(t, err)
+	flat := kdtree.NewKDTreeWithValues(3, vs)
+
+	// A query far from every edge only needs its own image.
+	center := points.Float3D{5, 5, 5}
+	var periodicStats, flatStats kdtree.QueryStats
+	periodic.NearestNeighborWithStats(center, &periodicStats)
+	flat.NearestNeighborWithStats(center, &flatStats)
+	assert.Equal(t, flatStats, periodicStats)
+	periodicStats, flatStats = kdtree.QueryStats{}, kdtree.QueryStats{}
+	periodic.KNNWithStats(center, 5, &periodicStats)
+	flat.KNNWithStats(center, 5, &flatStats)
+	assert.Equal(t, flatStats, periodicStats)
+	periodicStats, flatStats = kdtree.QueryStats{}, kdtree.QueryStats{}
+	periodic.RadiusSearchWithStats(center, kdtree.FloatDistKey(1), &periodicStats)
+	flat.RadiusSearchWithStats(center, kdtree.FloatDistKey(1), &flatStats)
+	assert.Equal(t, flatStats, periodicStats)
+
+	for i := 0; i < 100; i++ {
+		q := random()
+		if i%2 == 0 {
+			q[i%3] = math.Mod(q[i%3], 0.5)
+		}
+		byDist := slices.Clone(vs)
+		slices.SortFunc(byDist, func(a, b points.Float3D) int {
+			return kdtree.FloatDistKey(wrappedDist3D(q, a, period)) - kdtree.FloatDistKey(wrappedDist3D(q, b, period))
+		})
+
+		nn, ok := periodic.NearestNeighbor(q)
+		assert.True(t, ok)
+		assert.Equal(t, wrappedDist3D(q, byDist[0], period), wrappedDist3D(q, nn, period))
+
+		knn := periodic.KNN(q, 5)
+		assert.Len(t, knn, 5)
+		for j, v := range knn {
+			assert.Equal(t, wrappedDist3D(q, byDist[4-j], period), wrappedDist3D(q, v, period))
+		}
+
+		var want []points.Float3D
+		for _, v := range vs {
+			if wrappedDist3D(q, v, period) <= 1 {
+				want = append(want, v)
+			}
+		}
+		assert.ElementsMatch(t, want, periodic.RadiusSearch(q, kdtree.FloatDistKey(1)))
+	}
+}
+
+func TestPeriodicOutsidePeriod(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(2, []points.Float2D{{1, 2}, {10, 3}})
+	assert.ErrorIs(t, tree.SetPeriodic([]float64{10, 10}), kdtree.ErrOutsidePeriod)
+	assert.NoError(t, tree.SetPeriodic([]float64{11, 10}))
+	assert.ErrorIs(t, tree.Insert(points.Float2D{-1, 5}), kdtree.ErrOutsidePeriod)
+	assert.ErrorIs(t, tree.Insert(points.Float2D{5, 10}), kdtree.ErrOutsidePeriod)
+	assert.NoError(t, tree.Insert(points.Float2D{5, 9.5}))
+	assert.Equal(t, 3, tree.Len())
+
+	_, err := kdtree.New(2,
+		kdtree.WithPeriods[points.Float2D]([]float64{10, 10}),
+		kdtree.WithValues([]points.Float2D{{1, 2}, {3, 12}}),
+	)
+	assert.ErrorIs(t, err, kdtree.ErrOutsidePeriod)
+}
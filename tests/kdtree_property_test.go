@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	types "github.com/rishitc/go-kd-tree/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomTensor2D(rng *rand.Rand) types.Tensor2D {
+	return types.Tensor2D{rng.Intn(10), rng.Intn(10)}
+}
+
+func TestPropertySize(t *testing.T) {
+	for seed := int64(0); seed < 30; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		var tree *kdtree.KDTree[types.Tensor2D]
+		if seed%2 == 0 {
+			tree, _ = kdtree.New[types.Tensor2D](dimensions2DCount)
+		} else {
+			vs := make([]types.Tensor2D, 20)
+			for i := range vs {
+				vs[i] = randomTensor2D(rng)
+			}
+			tree = kdtree.NewKDTreeWithValues(dimensions2DCount, vs)
+		}
+		want := map[types.Tensor2D]bool{}
+		for _, v := range tree.Values() {
+			want[v] = true
+		}
+
+		for step := 0; step < 300; step++ {
+			v := randomTensor2D(rng)
+			switch op := rng.Intn(10); op {
+			case 0, 1, 2:
+				assert.NoError(t, tree.Insert(v))
+				want[v] = true
+			case 3, 4:
+				assert.Equal(t, want[v], tree.Remove(v))
+				delete(want, v)
+			case 5:
+				w := randomTensor2D(rng)
+				assert.Equal(t, want[v], tree.Update(v, w))
+				if want[v] {
+					delete(want, v)
+					want[w] = true
+				}
+			case 6:
+				removed := tree.RemoveWhere(func(u types.Tensor2D) bool { return u[0] == v[0] })
+				n := 0
+				for u := range want {
+					if u[0] == v[0] {
+						delete(want, u)
+						n++
+					}
+				}
+				assert.Equal(t, n, removed)
+			case 7:
+				tree.SetLazyDelete(rng.Intn(2) == 0, 0.3)
+			case 8:
+				tree.Balance()
+			case 9:
+				other := kdtree.NewKDTreeWithValues(dimensions2DCount, []types.Tensor2D{v, v})
+				merged, err := kdtree.Merge(tree, other)
+				assert.NoError(t, err)
+				tree = merged
+				want[v] = true
+			}
+
+			assert.Equal(t, len(want), tree.Len(), "seed %d, step %d", seed, step)
+			assert.Equal(t, tree.Len(), len(tree.Values()))
+			wantValues := make([]types.Tensor2D, 0, len(want))
+			for u := range want {
+				wantValues = append(wantValues, u)
+			}
+			assert.ElementsMatch(t, wantValues, tree.Values())
+			assert.NoError(t, tree.Validate())
+		}
+
+		b, err := tree.EncodeWith(types.Tensor2DFixedCodec{})
+		assert.NoError(t, err)
+		decoded, err := kdtree.NewKDTreeFromBytesWith(b, types.Tensor2DFixedCodec{})
+		assert.NoError(t, err)
+		assert.Equal(t, tree.Len(), decoded.Len())
+		assert.Equal(t, dimensions2DCount, decoded.Dimensions())
+	}
+}
@@ -0,0 +1,281 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+)
+
+// oracleVec is a point with any number of small integer coordinates, so that random points often tie in
+// some of their dimensions.
+type oracleVec []int
+
+func (lhs oracleVec) Order(rhs oracleVec, dim int) int {
+	for range lhs {
+		if lhs[dim] < rhs[dim] {
+			return -1
+		} else if lhs[dim] > rhs[dim] {
+			return 1
+		}
+		dim = (dim + 1) % len(lhs)
+	}
+	return 0
+}
+
+func (lhs oracleVec) Dist(rhs oracleVec) int {
+	sum := 0
+	for i := range lhs {
+		sum += (lhs[i] - rhs[i]) * (lhs[i] - rhs[i])
+	}
+	return sum
+}
+
+func (lhs oracleVec) DistDim(rhs oracleVec, dim int) int {
+	return (lhs[dim] - rhs[dim]) * (lhs[dim] - rhs[dim])
+}
+
+func (lhs oracleVec) String() string {
+	return fmt.Sprint([]int(lhs))
+}
+
+// opSource drives the oracle, either from a random number generator or from fuzzer input.
+type opSource interface {
+	intn(n int) int
+	done() bool
+}
+
+type randSource struct {
+	rng   *rand.Rand
+	steps int
+}
+
+func (s *randSource) intn(n int) int {
+	return s.rng.Intn(n)
+}
+
+func (s *randSource) done() bool {
+	s.steps--
+	return s.steps < 0
+}
+
+type byteSource struct {
+	data []byte
+}
+
+func (s *byteSource) intn(n int) int {
+	if len(s.data) == 0 {
+		return 0
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return int(b) % n
+}
+
+func (s *byteSource) done() bool {
+	return len(s.data) == 0
+}
+
+// oracle applies the same operations to a tree and to a plain slice and checks that every query of the
+// tree agrees with a linear scan of the slice.
+type oracle struct {
+	t    testing.TB
+	dims int
+	tree *kdtree.KDTree[oracleVec]
+	ref  []oracleVec
+	log  []string
+}
+
+const oracleCoordinateRange = 8
+
+func newOracle(t testing.TB, dims int) *oracle {
+	tree, err := kdtree.New[oracleVec](dims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &oracle{t: t, dims: dims, tree: tree}
+}
+
+func (o *oracle) fatalf(format string, args ...any) {
+	o.t.Helper()
+	o.t.Fatalf("%s\noperations:\n%v\ntree:\n%s", fmt.Sprintf(format, args...), o.log, o.tree)
+}
+
+func (o *oracle) point(src opSource) oracleVec {
+	v := make(oracleVec, o.dims)
+	for i := range v {
+		v[i] = src.intn(oracleCoordinateRange)
+	}
+	return v
+}
+
+func (o *oracle) contains(v oracleVec) bool {
+	return slices.ContainsFunc(o.ref, func(r oracleVec) bool { return r.Dist(v) == 0 })
+}
+
+func (o *oracle) run(src opSource) {
+	o.t.Helper()
+	for !src.done() {
+		o.step(src)
+		if o.tree.Len() != len(o.ref) {
+			o.fatalf("Len() = %d, want %d", o.tree.Len(), len(o.ref))
+		}
+		if err := o.tree.Validate(); err != nil {
+			o.fatalf("Validate() = %v", err)
+		}
+	}
+}
+
+func (o *oracle) step(src opSource) {
+	o.t.Helper()
+	switch op := src.intn(10); op {
+	case 0, 1:
+		v := o.point(src)
+		o.log = append(o.log, fmt.Sprintf("Insert(%v)", v))
+		if err := o.tree.Insert(v); err != nil {
+			o.fatalf("Insert(%v) = %v", v, err)
+		}
+		if !o.contains(v) {
+			o.ref = append(o.ref, v)
+		}
+	case 2, 3:
+		v := o.point(src)
+		if len(o.ref) > 0 && src.intn(2) == 0 {
+			v = o.ref[src.intn(len(o.ref))]
+		}
+		o.log = append(o.log, fmt.Sprintf("Remove(%v)", v))
+		want := o.contains(v)
+		if got := o.tree.Remove(v); got != want {
+			o.fatalf("Remove(%v) = %v, want %v", v, got, want)
+		}
+		o.ref = slices.DeleteFunc(o.ref, func(r oracleVec) bool { return r.Dist(v) == 0 })
+	case 4:
+		o.log = append(o.log, "Balance()")
+		o.tree.Balance()
+	case 5:
+		enabled, ratio := src.intn(2) == 0, []float64{0, 0.25}[src.intn(2)]
+		o.log = append(o.log, fmt.Sprintf("SetLazyDelete(%v, %v)", enabled, ratio))
+		if err := o.tree.SetLazyDelete(enabled, ratio); err != nil {
+			o.fatalf("SetLazyDelete(%v, %v) = %v", enabled, ratio, err)
+		}
+	case 6:
+		q := o.point(src)
+		got, ok := o.tree.NearestNeighbor(q)
+		if ok != (len(o.ref) > 0) {
+			o.fatalf("NearestNeighbor(%v) found a value: %v, tree holds %d values", q, ok, len(o.ref))
+		}
+		if ok {
+			want := o.byDist(q)[0]
+			if !o.contains(got) || q.Dist(got) != q.Dist(want) {
+				o.fatalf("NearestNeighbor(%v) = %v, want %v", q, got, want)
+			}
+		}
+	case 7:
+		q, k := o.point(src), 1+src.intn(4)
+		got := o.tree.KNN(q, k)
+		if k > len(o.ref) {
+			if got != nil {
+				o.fatalf("KNN(%v, %d) = %v with only %d values", q, k, got, len(o.ref))
+			}
+			return
+		}
+		want := o.byDist(q)[:k]
+		gotDists, wantDists := make([]int, k), make([]int, k)
+		for i := range want {
+			if !o.contains(got[i]) {
+				o.fatalf("KNN(%v, %d) = %v, which holds a value not in the tree", q, k, got)
+			}
+			gotDists[i], wantDists[i] = q.Dist(got[i]), q.Dist(want[i])
+		}
+		slices.Sort(gotDists)
+		if !slices.Equal(gotDists, wantDists) {
+			o.fatalf("KNN(%v, %d) = %v, want %v", q, k, got, want)
+		}
+	case 8:
+		lo, hi := o.point(src), o.point(src)
+		for i := range lo {
+			lo[i], hi[i] = min(lo[i], hi[i]), max(lo[i], hi[i])
+		}
+		var want []oracleVec
+		for _, r := range o.ref {
+			if boxPosition(lo, hi, r, -1) == kdtree.InRange {
+				want = append(want, r)
+			}
+		}
+		got := o.tree.RangeSearch(func(v oracleVec, dim int) kdtree.RelativePosition {
+			return boxPosition(lo, hi, v, dim)
+		})
+		slices.SortFunc(got, func(a, b oracleVec) int { return a.Order(b, 0) })
+		slices.SortFunc(want, func(a, b oracleVec) int { return a.Order(b, 0) })
+		if !slices.EqualFunc(got, want, func(a, b oracleVec) bool { return a.Dist(b) == 0 }) {
+			o.fatalf("RangeSearch(%v, %v) = %v, want %v", lo, hi, got, want)
+		}
+	case 9:
+		dim, findMax := src.intn(o.dims), src.intn(2) == 0
+		find, name := o.tree.FindMin, "FindMin"
+		if findMax {
+			find, name = o.tree.FindMax, "FindMax"
+		}
+		got, err := find(dim)
+		if len(o.ref) == 0 {
+			if !errors.Is(err, kdtree.ErrEmptyTree) {
+				o.fatalf("%s(%d) = %v, %v on an empty tree", name, dim, got, err)
+			}
+			return
+		}
+		want := slices.MinFunc(o.ref, func(a, b oracleVec) int { return a.Order(b, dim) })
+		if findMax {
+			want = slices.MaxFunc(o.ref, func(a, b oracleVec) int { return a.Order(b, dim) })
+		}
+		if err != nil || got.Dist(want) != 0 {
+			o.fatalf("%s(%d) = %v, %v, want %v", name, dim, got, err, want)
+		}
+	}
+}
+
+// byDist returns the values of the oracle ordered by their distance to q.
+func (o *oracle) byDist(q oracleVec) []oracleVec {
+	res := slices.Clone(o.ref)
+	slices.SortStableFunc(res, func(a, b oracleVec) int { return q.Dist(a) - q.Dist(b) })
+	return res
+}
+
+func boxPosition(lo, hi, v oracleVec, dim int) kdtree.RelativePosition {
+	if dim >= 0 {
+		if v[dim] < lo[dim] {
+			return kdtree.BeforeRange
+		} else if v[dim] > hi[dim] {
+			return kdtree.AfterRange
+		}
+		return kdtree.InRange
+	}
+	for d := range v {
+		if rel := boxPosition(lo, hi, v, d); rel != kdtree.InRange {
+			return rel
+		}
+	}
+	return kdtree.InRange
+}
+
+func TestOracle(t *testing.T) {
+	for dims := 1; dims <= 8; dims++ {
+		for seed := int64(0); seed < 20; seed++ {
+			t.Run(fmt.Sprintf("%dD/seed%d", dims, seed), func(t *testing.T) {
+				rng := rand.New(rand.NewSource(seed))
+				newOracle(t, dims).run(&randSource{rng: rng, steps: 300})
+			})
+		}
+	}
+}
+
+func FuzzOracle(f *testing.F) {
+	f.Add(uint8(1), []byte{0, 1, 0, 2, 6, 3, 7, 1, 2})
+	f.Add(uint8(2), []byte{0, 3, 2, 0, 3, 3, 1, 1, 1, 5, 0, 1, 2, 0, 3, 2, 9, 1, 0})
+	f.Add(uint8(3), []byte{1, 0, 0, 0, 1, 0, 0, 1, 8, 0, 0, 0, 7, 7, 7, 4, 6, 1, 1, 1})
+	f.Fuzz(func(t *testing.T, dims uint8, ops []byte) {
+		newOracle(t, 1+int(dims)%8).run(&byteSource{data: ops})
+	})
+}
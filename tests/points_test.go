@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	kdtree "github.com/rishitc/go-kd-tree"
+	"github.com/rishitc/go-kd-tree/points"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointsOrder(t *testing.T) {
+	assert.Equal(t, -1, points.Int3D{1, 5, 0}.Order(points.Int3D{2, 0, 0}, 0))
+	assert.Equal(t, 1, points.Int3D{1, 5, 0}.Order(points.Int3D{1, 4, 9}, 0))
+	assert.Equal(t, -1, points.Int3D{1, 5, 0}.Order(points.Int3D{1, 5, 9}, 1))
+	assert.Equal(t, 0, points.Int3D{1, 5, 0}.Order(points.Int3D{1, 5, 0}, 2))
+	assert.Equal(t, 1, points.Float2D{0.5, 1}.Order(points.Float2D{0.5, 0.25}, 0))
+	assert.Equal(t, -1, points.VecF64{1, 2, 3, 4}.Order(points.VecF64{1, 2, 3, 5}, 3))
+	assert.Equal(t, 1, points.VecF64{1, 2, 3, 4}.Order(points.VecF64{0, 2, 3, 4}, 3))
+}
+
+func TestPointsFloatDist(t *testing.T) {
+	a, b, c := points.Float2D{0, 0}, points.Float2D{0.1, 0.1}, points.Float2D{0.1, 0.2}
+	assert.Equal(t, 0, a.Dist(a))
+	assert.Less(t, a.Dist(b), a.Dist(c))
+	assert.InDelta(t, 0.05, kdtree.FloatDistFromKey(a.Dist(c)), 1e-9)
+	assert.Less(t, a.DistDim(c, 0), a.Dist(c))
+}
+
+// TestFloatDistKeyOrder also runs with GOARCH=386, where int has 32 bits.
+func TestFloatDistKeyOrder(t *testing.T) {
+	ds := []float64{0, 1e-9, 0.5, 1, 2.5, 4, 1e9}
+	for i, d := range ds {
+		key := kdtree.FloatDistKey(d)
+		assert.InDelta(t, d, kdtree.FloatDistFromKey(key), d*1e-6, "distance %v", d)
+		if i > 0 {
+			assert.Less(t, kdtree.FloatDistKey(ds[i-1]), key, "distances %v and %v", ds[i-1], d)
+		}
+	}
+}
+
+func TestPointsNearestNeighbor(t *testing.T) {
+	const dims = 5
+	rng := rand.New(rand.NewSource(41))
+	vs := make([]points.VecF64, 200)
+	for i := range vs {
+		vs[i] = make(points.VecF64, dims)
+		for j := range vs[i] {
+			vs[i][j] = rng.Float64()
+		}
+	}
+	tree := kdtree.NewKDTreeWithValues(dims, vs)
+	for i := 0; i < 50; i++ {
+		q := make(points.VecF64, dims)
+		for j := range q {
+			q[j] = rng.Float64()
+		}
+		want := vs[0]
+		for _, v := range vs[1:] {
+			if q.Dist(v) < q.Dist(want) {
+				want = v
+			}
+		}
+		got, ok := tree.NearestNeighbor(q)
+		assert.True(t, ok)
+		assert.Equal(t, q.Dist(want), q.Dist(got))
+	}
+}
+
+func TestPointsEncodeWithoutMethod(t *testing.T) {
+	tree := kdtree.NewKDTreeWithValues(2, []points.Int2D{{1, 2}, {3, 4}})
+	assert.PanicsWithValue(t, "points.Int2D has no Encode() []byte method, use EncodeWith", func() { tree.Encode() })
+	_, err := tree.EncodeWith(points.Int2DCodec{})
+	assert.NoError(t, err)
+}
+
+func TestPointsCodecs(t *testing.T) {
+	ints := kdtree.NewKDTreeWithValues(3, []points.Int3D{{1, 2, 3}, {-4, 5, 6}, {7, -8, 1 << 30}})
+	b, err := ints.EncodeWith(points.Int3DCodec{})
+	assert.NoError(t, err)
+	decodedInts, err := kdtree.NewKDTreeFromBytesWith(b, points.Int3DCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decodedInts.Equal(ints))
+
+	floats := kdtree.NewKDTreeWithValues(2, []points.Float2D{{0.5, -1.25}, {3, 2}, {-0.125, 1e300}})
+	b, err = floats.EncodeWith(points.Float2DCodec{})
+	assert.NoError(t, err)
+	decodedFloats, err := kdtree.NewKDTreeFromBytesWith(b, points.Float2DCodec{})
+	assert.NoError(t, err)
+	assert.True(t, decodedFloats.Equal(floats))
+
+	vecs := kdtree.NewKDTreeWithValues(4, []points.VecF64{{1, 2, 3, 4}, {4, 3, 2, 1}, {0, 0, 0, 0.5}})
+	b, err = vecs.EncodeWith(points.VecF64Codec{Dimensions: 4})
+	assert.NoError(t, err)
+	decodedVecs, err := kdtree.NewKDTreeFromBytesWith(b, points.VecF64Codec{Dimensions: 4})
+	assert.NoError(t, err)
+	assert.True(t, decodedVecs.Equal(vecs))
+	_, err = kdtree.NewKDTreeFromBytesWith(b, points.VecF64Codec{Dimensions: 3})
+	assert.Error(t, err)
+}
+
+func TestPointsLatLon(t *testing.T) {
+	london, paris := points.LatLon{Lat: 51.5074, Lon: -0.1278}, points.LatLon{Lat: 48.8566, Lon: 2.3522}
+	assert.InDelta(t, 343_500, london.DistanceMetres(paris), 1_000)
+
+	rng := rand.New(rand.NewSource(42))
+	random := func() points.LatLon {
+		lon := rng.Float64()*360 - 180
+		if rng.Intn(2) == 0 {
+			lon = 180 - rng.Float64()*4
+			if rng.Intn(2) == 0 {
+				lon = -lon
+			}
+		}
+		return points.LatLon{Lat: rng.Float64()*170 - 85, Lon: lon}
+	}
+	vs := make([]points.LatLon, 300)
+	for i := range vs {
+		vs[i] = random()
+	}
+	tree := kdtree.NewKDTreeWithValues(2, vs)
+
+	for i := 0; i < 100; i++ {
+		q := random()
+		byDist := slices.Clone(vs)
+		slices.SortFunc(byDist, func(a, b points.LatLon) int { return q.Dist(a) - q.Dist(b) })
+
+		nn, ok := tree.NearestNeighbor(q)
+		assert.True(t, ok)
+		assert.Equal(t, q.Dist(byDist[0]), q.Dist(nn))
+
+		knn := tree.KNN(q, 5)
+		assert.Len(t, knn, 5)
+		for _, v := range knn {
+			assert.LessOrEqual(t, q.Dist(v), q.Dist(byDist[4]))
+		}
+
+		radius := points.Metres(500_000)
+		var want []points.LatLon
+		for _, v := range vs {
+			if q.Dist(v) <= radius {
+				want = append(want, v)
+			}
+		}
+		assert.ElementsMatch(t, want, tree.RadiusSearch(q, radius))
+	}
+
+	// The nearest neighbour lies across the antimeridian.
+	tree = kdtree.NewKDTreeWithValues(2, []points.LatLon{
+		{Lat: 0, Lon: 179.9},
+		{Lat: 0, Lon: -170},
+		{Lat: 0, Lon: 100},
+		{Lat: 10, Lon: -179},
+	})
+	nn, _ := tree.NearestNeighbor(points.LatLon{Lat: 0, Lon: -179.95})
+	assert.Equal(t, points.LatLon{Lat: 0, Lon: 179.9}, nn)
+	assert.ElementsMatch(t, []points.LatLon{{Lat: 0, Lon: 179.9}}, tree.RadiusSearch(points.LatLon{Lat: 0, Lon: -179.95}, points.Metres(20_000)))
+}